@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// follow copies the file at path to w as it grows until ctx is done. When the
+// file is rotated, the rest of the old file is copied before the new one is
+// opened at path; when it is truncated, it is read again from the start. A
+// missing file is waited for.
+func follow(ctx context.Context, path string, w io.Writer, poll time.Duration) error {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	var file *os.File
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	for {
+		if file == nil {
+			f, err := os.Open(path)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			file = f
+		}
+
+		if file != nil {
+			if _, err := io.Copy(w, file); err != nil {
+				return err
+			}
+			rotated, err := checkRotation(path, file, w)
+			if err != nil {
+				return err
+			}
+			if rotated {
+				file.Close()
+				file = nil
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkRotation reports whether path now names another file than file, after
+// copying what was written to file in the meantime. A truncated file is
+// rewound instead.
+func checkRotation(path string, file *os.File, w io.Writer) (bool, error) {
+	latest, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Renamed away and not created again yet; keep reading the old file
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	current, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	if !os.SameFile(current, latest) {
+		if _, err := io.Copy(w, file); err != nil {
+			return false, err
+		}
+		// End a last line left without a newline before the new file starts
+		_, err := io.WriteString(w, "\n")
+		return true, err
+	}
+
+	offset, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, err
+	}
+	if latest.Size() < offset {
+		_, err := file.Seek(0, io.SeekStart)
+		return false, err
+	}
+	return false, nil
+}
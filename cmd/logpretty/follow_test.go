@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while follow writes to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func waitFor(t *testing.T, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %q, got %q", want, out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFollowAcrossRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "one\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out syncBuffer
+	done := make(chan error)
+	go func() { done <- follow(ctx, path, &out, 5*time.Millisecond) }()
+
+	waitFor(t, &out, "one\n")
+	appendFile(t, path, "two\nunfinished")
+
+	// Rotate: rename the file away and start a new one at path
+	waitFor(t, &out, "two\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path+".1", " line\n")
+	appendFile(t, path, "three\n")
+	waitFor(t, &out, "three\n")
+
+	// Truncate in place, as copytruncate does
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	appendFile(t, path, "four\n")
+	waitFor(t, &out, "four\n")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "one\ntwo\nunfinished line\n\nthree\nfour\n"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
+
+func TestRunRendersFilesAndStdin(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.log")
+	second := filepath.Join(dir, "b.log")
+	appendFile(t, first, `{"level":"INFO","msg":"from a","user_id":"u-1"}`)
+	appendFile(t, second, `{"level":"ERROR","msg":"from b","trace":["main.go:10"]}`+"\n")
+
+	var stdout, stderr bytes.Buffer
+	stdin := strings.NewReader(`{"level":"WARN","msg":"from stdin"}` + "\n")
+	code := run(context.Background(), []string{"-color", "never", "-timestamp=false", first, "-", second}, stdin, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	want := "[INFO] from a | u-1\n" +
+		"[WARN] from stdin\n" +
+		"[ERROR] from b\nStack Trace:\n  1. main.go:10\n"
+	if stdout.String() != want {
+		t.Errorf("Expected %q, got %q", want, stdout.String())
+	}
+}
+
+func TestRunFollowNeedsOneFile(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"-f"}, strings.NewReader(""), &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+}
@@ -0,0 +1,105 @@
+// Command logpretty renders NDJSON log records, such as the files written by
+// the JSON backend, in the console layout of the pretty handler.
+//
+// Usage:
+//
+//	logpretty [flags] [file ...]
+//
+// With no files, or with -, records are read from stdin. With -f, logpretty
+// keeps reading a single file as it grows and follows it across rotation.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/aaffriya/logger"
+	"github.com/aaffriya/logger/config"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("logpretty", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: logpretty [flags] [file ...]")
+		flags.PrintDefaults()
+	}
+
+	var pretty config.PrettyConfig
+	followFile := flags.Bool("f", false, "follow the file as it grows, across rotation")
+	poll := flags.Duration("poll", 250*time.Millisecond, "how often to check a followed file")
+	flags.BoolVar(&pretty.IsJsonOutput, "json", false, "render attributes as indented JSON instead of text")
+	flags.BoolVar(&pretty.IncludeTimestamp, "timestamp", true, "show record timestamps")
+	flags.StringVar(&pretty.Color, "color", config.ColorAuto, "color mode: auto, always or never")
+	flags.StringVar(&pretty.Theme.Name, "theme", config.ThemeDark, "color theme: dark, light or high-contrast")
+	flags.BoolVar(&pretty.Compact, "compact", false, "write each record on one line")
+	flags.BoolVar(&pretty.Columns, "columns", false, "lay records out in columns")
+	flags.BoolVar(&pretty.Humanize, "humanize", false, "show durations, sizes and times for people")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	files := flags.Args()
+	if *followFile && len(files) != 1 {
+		fmt.Fprintln(stderr, "logpretty: -f needs exactly one file")
+		return 2
+	}
+
+	w := logger.NewPrettyJSONWriter(stdout, &pretty)
+	err := render(ctx, files, stdin, w, *followFile, *poll)
+	err = errors.Join(err, w.Close())
+	if err != nil {
+		fmt.Fprintln(stderr, "logpretty:", err)
+		return 1
+	}
+	return 0
+}
+
+// render copies the files, or stdin, to w
+func render(ctx context.Context, files []string, stdin io.Reader, w io.Writer, followFile bool, poll time.Duration) error {
+	if followFile {
+		return follow(ctx, files[0], w, poll)
+	}
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, name := range files {
+		if err := copyFile(name, stdin, w); err != nil {
+			return err
+		}
+		// A last line without a newline is not joined with the next file
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies the file name, or stdin for -, to w
+func copyFile(name string, stdin io.Reader, w io.Writer) error {
+	if name == "-" {
+		_, err := io.Copy(w, stdin)
+		return err
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
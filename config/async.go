@@ -0,0 +1,33 @@
+package config
+
+import "time"
+
+// Backpressure policies applied when the async queue is full
+const (
+	BackpressureBlock      = "block"       // wait for space in the queue
+	BackpressureDropNewest = "drop_newest" // discard the incoming record
+	BackpressureDropOldest = "drop_oldest" // evict the oldest queued record that may be dropped
+	BackpressureSample     = "sample"      // keep one of every SampleRate records, discard the rest
+)
+
+// AsyncConfig moves formatting and writing off the logging goroutine. Records
+// are queued and written by a background goroutine; call logger.Flush or
+// logger.Close before exit so queued records are not lost.
+type AsyncConfig struct {
+	Enabled       bool               `yaml:"enabled"        json:"enabled"`
+	QueueSize     int                `yaml:"queue_size"     json:"queue_size"`     // records, default 1024
+	FlushInterval time.Duration      `yaml:"flush_interval" json:"flush_interval"` // default 1s
+	BufferSize    int                `yaml:"buffer_size"    json:"buffer_size"`    // bytes buffered by file backends, default 64 KiB
+	Backpressure  BackpressureConfig `yaml:"backpressure"   json:"backpressure"`
+}
+
+// BackpressureConfig picks what happens to a record of each level when the
+// queue is full. Empty policies block.
+type BackpressureConfig struct {
+	Error          string        `yaml:"error"           json:"error"`
+	Warn           string        `yaml:"warn"            json:"warn"`
+	Info           string        `yaml:"info"            json:"info"`
+	Debug          string        `yaml:"debug"           json:"debug"`
+	SampleRate     int           `yaml:"sample_rate"     json:"sample_rate"`     // default 10
+	ReportInterval time.Duration `yaml:"report_interval" json:"report_interval"` // how often drops are logged, default 10s
+}
@@ -238,4 +238,23 @@ func TestCompleteLoggerConfig(t *testing.T) {
 	if config.Pretty.IsJsonOutput {
 		t.Error("Expected IsJsonOutput to be false")
 	}
-}
\ No newline at end of file
+}
+
+func TestThemeConfigFromJSON(t *testing.T) {
+	data := `{"color": "always", "theme": {"name": "light", "message": "bold #ff8700", "levels": {"error": "196"}, "fields": {"user_id": "underline blue"}}}`
+
+	var pretty PrettyConfig
+	if err := json.Unmarshal([]byte(data), &pretty); err != nil {
+		t.Fatalf("Failed to unmarshal pretty config from JSON: %v", err)
+	}
+
+	if pretty.Color != ColorAlways {
+		t.Errorf("Expected Color %s, got %s", ColorAlways, pretty.Color)
+	}
+	if pretty.Theme.Name != ThemeLight || pretty.Theme.Message != "bold #ff8700" {
+		t.Errorf("Expected the light theme with a message color, got %+v", pretty.Theme)
+	}
+	if pretty.Theme.Levels.Error != "196" || pretty.Theme.Fields["user_id"] != "underline blue" {
+		t.Errorf("Expected level and field colors, got %+v", pretty.Theme)
+	}
+}
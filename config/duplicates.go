@@ -0,0 +1,10 @@
+package config
+
+// Duplicate key policies for attributes with the same key in the same group.
+// Keys the logger adds itself, such as trace, service and version, are never
+// replaced: a clashing attribute is renamed, or dropped under keep_first.
+const (
+	DuplicateKeepLast  = "keep_last"  // a later attribute replaces an earlier one, the default
+	DuplicateKeepFirst = "keep_first" // later attributes with a taken key are dropped
+	DuplicateRename    = "rename"     // later attributes get a suffix: key_2, key_3...
+)
@@ -0,0 +1,25 @@
+package config
+
+// Duration encodings
+const (
+	DurationString  = "string"  // Go duration string such as 1.5s, the default
+	DurationNanos   = "nanos"   // integer nanoseconds, like slog.JSONHandler
+	DurationMillis  = "millis"  // float milliseconds
+	DurationSeconds = "seconds" // float seconds
+)
+
+// Time encodings. Any other non-empty value is used as a time.Format layout.
+const (
+	TimeRFC3339Nano = "rfc3339nano" // the default
+	TimeRFC3339     = "rfc3339"
+	TimeUnix        = "unix"      // integer seconds since the epoch
+	TimeUnixMilli   = "unix_ms"   // integer milliseconds since the epoch
+	TimeUnixNano    = "unix_nano" // integer nanoseconds since the epoch
+)
+
+// EncodingConfig controls how attribute values are encoded by every format.
+// It does not change the record timestamp.
+type EncodingConfig struct {
+	Duration string `yaml:"duration" json:"duration"`
+	Time     string `yaml:"time"     json:"time"`
+}
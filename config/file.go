@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+type FileConfig struct {
+	Path      string          `yaml:"path"      json:"path"`
+	Rotation  RotationConfig  `yaml:"rotation"  json:"rotation"`
+	Retention RetentionConfig `yaml:"retention" json:"retention"`
+}
+
+type RotationConfig struct {
+	MaxSize    int64  `yaml:"max_size"    json:"max_size"`    // bytes, 0 disables size-based rotation
+	MaxBackups int    `yaml:"max_backups" json:"max_backups"` // rotated files to keep, 0 keeps all, same as Retention.MaxCount
+	Compress   bool   `yaml:"compress"    json:"compress"`    // gzip rotated files
+	Interval   string `yaml:"interval"    json:"interval"`    // hourly, daily
+	Pattern    string `yaml:"pattern"     json:"pattern"`     // e.g. app-%Y-%m-%d.log, Path becomes a symlink to the active file
+}
+
+// RetentionConfig limits the rotated files kept next to the active log file.
+// Each limit is disabled when zero.
+type RetentionConfig struct {
+	MaxAge       time.Duration `yaml:"max_age"        json:"max_age"`        // remove rotated files older than this
+	MaxCount     int           `yaml:"max_count"      json:"max_count"`      // keep at most this many rotated files
+	MaxTotalSize int64         `yaml:"max_total_size" json:"max_total_size"` // bytes, including the active file
+}
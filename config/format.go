@@ -0,0 +1,11 @@
+package config
+
+// Output formats. Every format works with any io.Writer.
+const (
+	FormatAuto       = "auto"        // json for regular files, pretty for anything else; the default
+	FormatPretty     = "pretty"      // pretty-json when Pretty.IsJsonOutput is set, pretty-text otherwise
+	FormatPrettyText = "pretty-text" // colored multi-line console output
+	FormatPrettyJSON = "pretty-json" // colored, indented JSON for the console
+	FormatJSON       = "json"        // one JSON object per line
+	FormatLogfmt     = "logfmt"      // one line of key=value pairs per record
+)
@@ -4,7 +4,13 @@ type LoggerConfig struct {
 	Stack         StackConfig      `yaml:"stack"             json:"stack"`
 	Level         string           `yaml:"level"             json:"level"` // debug, info, warn, error
 	DefaultFields DefaultFieldInfo `yaml:"default_fields"    json:"default_fields"`
+	Format        string           `yaml:"format"            json:"format"` // auto, pretty, pretty-text, pretty-json, json, logfmt
 	Pretty        PrettyConfig     `yaml:"pretty"           json:"pretty"`
+	Encoding      EncodingConfig   `yaml:"encoding"          json:"encoding"`
+	DuplicateKeys string           `yaml:"duplicate_keys"    json:"duplicate_keys"` // keep_last, keep_first, rename
+	File          FileConfig       `yaml:"file"              json:"file"`
+	Async         AsyncConfig      `yaml:"async"             json:"async"`
+	Sinks         []SinkConfig     `yaml:"sinks"             json:"sinks"` // used by logger.SetupMultiLogger
 }
 
 type StackConfig struct {
@@ -1,9 +1,24 @@
 package config
 
-type PrettyConfig struct {
-	IncludeTimestamp bool             `yaml:"include_timestamp" json:"include_timestamp"`
-	IsJsonOutput     bool             `yaml:"is_json_output"    json:"is_json_output"`
-}
+import "github.com/aaffriya/logger/pkg/pretty"
 
+// Color modes of the console formats
+const (
+	ColorAuto   = "auto"   // colors on a terminal, honoring NO_COLOR and FORCE_COLOR, the default
+	ColorAlways = "always" // colors everywhere
+	ColorNever  = "never"  // plain text with the same layout
+)
 
+type PrettyConfig struct {
+	IncludeTimestamp bool        `yaml:"include_timestamp" json:"include_timestamp"`
+	IsJsonOutput     bool        `yaml:"is_json_output"    json:"is_json_output"`
+	Color            string      `yaml:"color"             json:"color"` // auto, always, never
+	Theme            ThemeConfig `yaml:"theme"             json:"theme"`
+	Compact          bool        `yaml:"compact"           json:"compact"`  // one line per record in pretty-text
+	Columns          bool        `yaml:"columns"           json:"columns"`  // fixed-width columns, values wrapped to the terminal
+	Humanize         bool        `yaml:"humanize"          json:"humanize"` // durations, sizes, times and long values for people in pretty-text
 
+	// Rules pick the colors and display of values, nil applies the default
+	// rules only
+	Rules *pretty.Registry `yaml:"-" json:"-"`
+}
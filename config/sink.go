@@ -0,0 +1,22 @@
+package config
+
+// Sink outputs other than a file path
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+)
+
+// SinkConfig is one output of a logger that fans out to several sinks. Every
+// record is enriched once and handed to each sink whose level it passes.
+// Level, Format, Stack and Pretty fall back to the LoggerConfig values when
+// empty.
+type SinkConfig struct {
+	Name      string          `yaml:"name"      json:"name"`   // used in error messages
+	Output    string          `yaml:"output"    json:"output"` // stdout, stderr or a file path
+	Level     string          `yaml:"level"     json:"level"`  // debug, info, warn, error
+	Format    string          `yaml:"format"    json:"format"` // see LoggerConfig.Format
+	Stack     *StackConfig    `yaml:"stack"     json:"stack"`
+	Pretty    *PrettyConfig   `yaml:"pretty"    json:"pretty"`
+	Rotation  RotationConfig  `yaml:"rotation"  json:"rotation"`  // file outputs only
+	Retention RetentionConfig `yaml:"retention" json:"retention"` // file outputs only
+}
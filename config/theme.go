@@ -0,0 +1,70 @@
+package config
+
+// Built-in console themes
+const (
+	ThemeDark         = "dark" // the default
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+)
+
+// ThemeConfig sets the colors of the console formats. Every color is a space
+// separated list of:
+//   - the styles bold, dim, italic and underline
+//   - a color name: black, red, green, yellow, blue, magenta, cyan, white,
+//     gray, or one of them prefixed with bright-
+//   - a 256-color palette index such as 208
+//   - a 24-bit color such as #ff8700
+//   - none, for the terminal's default
+//
+// Empty colors are taken from the theme named by Name.
+type ThemeConfig struct {
+	Name        string            `yaml:"name"        json:"name"` // dark, light, high-contrast
+	Timestamp   string            `yaml:"timestamp"   json:"timestamp"`
+	Message     string            `yaml:"message"     json:"message"`
+	Punctuation string            `yaml:"punctuation" json:"punctuation"` // brackets and separators
+	Label       string            `yaml:"label"       json:"label"`       // section labels such as Data:
+	Group       string            `yaml:"group"       json:"group"`
+	Trace       string            `yaml:"trace"       json:"trace"`
+	TraceFile   string            `yaml:"trace_file"  json:"trace_file"` // stack frames with a file and line
+	Levels      LevelColors       `yaml:"levels"      json:"levels"`
+	Values      ValueColors       `yaml:"values"      json:"values"`
+	JSON        JSONColors        `yaml:"json"        json:"json"`
+	Fields      map[string]string `yaml:"fields"      json:"fields"` // colors of the values of specific keys
+}
+
+type LevelColors struct {
+	Debug string `yaml:"debug" json:"debug"`
+	Info  string `yaml:"info"  json:"info"`
+	Warn  string `yaml:"warn"  json:"warn"`
+	Error string `yaml:"error" json:"error"`
+}
+
+// ValueColors are picked for attribute values by type and content
+type ValueColors struct {
+	String   string `yaml:"string"   json:"string"`
+	Number   string `yaml:"number"   json:"number"`
+	True     string `yaml:"true"     json:"true"`
+	False    string `yaml:"false"    json:"false"`
+	Object   string `yaml:"object"   json:"object"`
+	Array    string `yaml:"array"    json:"array"`
+	Link     string `yaml:"link"     json:"link"`    // URLs and email addresses
+	Address  string `yaml:"address"  json:"address"` // IP addresses and ports
+	Good     string `yaml:"good"     json:"good"`    // success, 2xx status codes, fast durations
+	Warning  string `yaml:"warning"  json:"warning"` // warnings, 4xx status codes, retries
+	Bad      string `yaml:"bad"      json:"bad"`     // errors, 5xx status codes, slow durations
+	Path     string `yaml:"path"     json:"path"`
+	Database string `yaml:"database" json:"database"`
+	Duration string `yaml:"duration" json:"duration"`
+}
+
+// JSONColors highlight the pretty-json Data block
+type JSONColors struct {
+	Key    string `yaml:"key"    json:"key"`
+	String string `yaml:"string" json:"string"`
+	Number string `yaml:"number" json:"number"`
+	Bool   string `yaml:"bool"   json:"bool"`
+	Null   string `yaml:"null"   json:"null"`
+	Brace  string `yaml:"brace"  json:"brace"`
+	Colon  string `yaml:"colon"  json:"colon"`
+	Comma  string `yaml:"comma"  json:"comma"`
+}
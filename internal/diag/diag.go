@@ -0,0 +1,41 @@
+// Package diag reports what the logger does on its own behalf, such as files
+// removed by retention or a failed compression. These events go to a separate
+// logger so they never re-enter the handler that triggered them.
+package diag
+
+import (
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	SetLogger(nil)
+}
+
+// SetLogger sets the destination for diagnostics, nil restores the default
+// text output on stderr
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = slog.New(slog.NewTextHandler(os.Stderr, nil)).With("component", "logger")
+	}
+	logger.Store(l)
+}
+
+func Logger() *slog.Logger {
+	return logger.Load()
+}
+
+func Info(msg string, args ...any) {
+	Logger().Info(msg, args...)
+}
+
+func Warn(msg string, args ...any) {
+	Logger().Warn(msg, args...)
+}
+
+func Error(msg string, args ...any) {
+	Logger().Error(msg, args...)
+}
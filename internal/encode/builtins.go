@@ -0,0 +1,29 @@
+package encode
+
+import "log/slog"
+
+// Builtins holds the values ReplaceAttr gave the time, level or message of a
+// record under their own keys when a record cannot hold them, such as a level
+// replaced by a string. Backends write them in place of the record fields.
+// The handler adds them to a record as its first attribute, with an empty
+// key. Fields left nil use the record.
+type Builtins struct {
+	Time    *slog.Value
+	Level   *slog.Value
+	Message *slog.Value
+}
+
+// BuiltinsOf returns the Builtins carried by r, or the zero Builtins
+func BuiltinsOf(r slog.Record) Builtins {
+	var b Builtins
+	r.Attrs(func(a slog.Attr) bool {
+		// Check the kind first, Any allocates for other kinds
+		if a.Key == "" && a.Value.Kind() == slog.KindAny {
+			if carried, ok := a.Value.Any().(*Builtins); ok {
+				b = *carried
+			}
+		}
+		return false
+	})
+	return b
+}
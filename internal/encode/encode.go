@@ -0,0 +1,202 @@
+// Package encode converts slog values for output. Every backend goes through
+// it, so a value is encoded the same way in JSON, logfmt and the console.
+package encode
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/aaffriya/logger/config"
+	"github.com/aaffriya/logger/internal/diag"
+)
+
+// Encoder applies the value-encoding policy:
+//   - LogValuers are resolved
+//   - errors become their message
+//   - encoding.TextMarshaler and fmt.Stringer are used where implemented,
+//     unless the value implements json.Marshaler
+//   - durations and times follow config.EncodingConfig
+//   - NaN and infinite floats become the strings "NaN", "+Inf" and "-Inf"
+//   - values json.Marshal rejects are formatted with %+v
+//
+// Struct fields and map values are left to encoding/json.
+type Encoder struct {
+	duration string
+	time     string
+}
+
+// New returns an Encoder for cfg, falling back to the defaults for unknown
+// duration encodings
+func New(cfg config.EncodingConfig) *Encoder {
+	e := &Encoder{duration: cfg.Duration, time: cfg.Time}
+	switch e.duration {
+	case config.DurationString, config.DurationNanos, config.DurationMillis, config.DurationSeconds:
+	case "":
+		e.duration = config.DurationString
+	default:
+		diag.Warn("unknown duration encoding, using string", "encoding", e.duration)
+		e.duration = config.DurationString
+	}
+	if e.time == "" {
+		e.time = config.TimeRFC3339Nano
+	}
+	return e
+}
+
+// Value returns v as nil, bool, int64, uint64, float64, string, a
+// map[string]any for groups, or a value json.Marshal is known to accept
+func (e *Encoder) Value(v slog.Value) any {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		return Float(v.Float64())
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return e.Duration(v.Duration())
+	case slog.KindTime:
+		return e.Time(v.Time())
+	case slog.KindGroup:
+		group := make(map[string]any, len(v.Group()))
+		for _, a := range v.Group() {
+			group[a.Key] = e.Value(a.Value)
+		}
+		return group
+	default:
+		return e.Any(v.Any())
+	}
+}
+
+// Any applies the policy to a value of any type and makes sure the result
+// can be passed to json.Marshal
+func (e *Encoder) Any(x any) any {
+	x = e.Convert(x)
+	switch x.(type) {
+	case nil, string, int64, uint64, float64, bool:
+		return x
+	}
+	if _, err := json.Marshal(x); err != nil {
+		return fmt.Sprintf("%+v", x)
+	}
+	return x
+}
+
+// Convert applies the type rules of the policy to x without checking that
+// the result can be marshaled. A method that panics, such as Error on a nil
+// pointer, is reported in place of the value.
+func (e *Encoder) Convert(x any) (converted any) {
+	defer func() {
+		if r := recover(); r != nil {
+			converted = fmt.Sprintf("!PANIC: %v", r)
+		}
+	}()
+
+	switch x := x.(type) {
+	case error:
+		return x.Error()
+	case time.Duration:
+		return e.Duration(x)
+	case time.Time:
+		return e.Time(x)
+	case float64:
+		return Float(x)
+	case float32:
+		return Float(float64(x))
+	case json.Marshaler:
+		return x
+	case encoding.TextMarshaler:
+		if text, err := x.MarshalText(); err == nil {
+			return string(text)
+		}
+	case fmt.Stringer:
+		return x.String()
+	}
+	return x
+}
+
+// String returns v as a single piece of text, for flat formats such as
+// logfmt. Groups are not expected; callers flatten them first.
+func (e *Encoder) String(v slog.Value) string {
+	v = v.Resolve()
+	if v.Kind() == slog.KindAny {
+		if src, ok := v.Any().(*slog.Source); ok {
+			return fmt.Sprintf("%s:%d", src.File, src.Line)
+		}
+	}
+
+	switch x := e.Value(v).(type) {
+	case string:
+		return x
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case uint64:
+		return strconv.FormatUint(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case nil:
+		return "null"
+	default:
+		if b, err := json.Marshal(x); err == nil {
+			return string(b)
+		}
+		return fmt.Sprintf("%+v", x)
+	}
+}
+
+// Duration encodes d according to the configured duration encoding
+func (e *Encoder) Duration(d time.Duration) any {
+	switch e.duration {
+	case config.DurationNanos:
+		return int64(d)
+	case config.DurationMillis:
+		return float64(d) / float64(time.Millisecond)
+	case config.DurationSeconds:
+		return d.Seconds()
+	default:
+		return d.String()
+	}
+}
+
+// Time encodes t according to the configured time encoding
+func (e *Encoder) Time(t time.Time) any {
+	switch e.time {
+	case config.TimeRFC3339Nano:
+		return t.Format(time.RFC3339Nano)
+	case config.TimeRFC3339:
+		return t.Format(time.RFC3339)
+	case config.TimeUnix:
+		return t.Unix()
+	case config.TimeUnixMilli:
+		return t.UnixMilli()
+	case config.TimeUnixNano:
+		return t.UnixNano()
+	default:
+		return t.Format(e.time)
+	}
+}
+
+// Float returns f, or a string for NaN and infinities which JSON cannot hold
+func Float(f float64) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return f
+}
@@ -0,0 +1,287 @@
+package encode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aaffriya/logger/config"
+)
+
+// AppendJSON appends v to buf as JSON. Group members keep their order.
+func (e *Encoder) AppendJSON(buf []byte, v slog.Value) []byte {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return AppendJSONString(buf, v.String())
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		return appendJSONAny(buf, Float(v.Float64()))
+	case slog.KindBool:
+		return strconv.AppendBool(buf, v.Bool())
+	case slog.KindDuration:
+		return e.appendJSONDuration(buf, v.Duration())
+	case slog.KindTime:
+		return e.appendJSONTime(buf, v.Time())
+	case slog.KindGroup:
+		buf = append(buf, '{')
+		for i, a := range v.Group() {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = AppendJSONString(buf, a.Key)
+			buf = append(buf, ':')
+			buf = e.AppendJSON(buf, a.Value)
+		}
+		return append(buf, '}')
+	}
+
+	// Stack traces are common enough to skip encoding/json
+	if trace, ok := v.Any().([]string); ok {
+		buf = append(buf, '[')
+		for i, frame := range trace {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = AppendJSONString(buf, frame)
+		}
+		return append(buf, ']')
+	}
+	return appendJSONAny(buf, e.Convert(v.Any()))
+}
+
+// appendJSONDuration appends d encoded like Duration without converting it
+// to an interface first
+func (e *Encoder) appendJSONDuration(buf []byte, d time.Duration) []byte {
+	switch e.duration {
+	case config.DurationNanos:
+		return strconv.AppendInt(buf, int64(d), 10)
+	case config.DurationMillis:
+		return appendJSONFloat(buf, float64(d)/float64(time.Millisecond))
+	case config.DurationSeconds:
+		return appendJSONFloat(buf, d.Seconds())
+	}
+	buf = append(buf, '"')
+	buf = appendDuration(buf, d)
+	return append(buf, '"')
+}
+
+// appendDuration appends the text of d.String(), which needs no escaping
+func appendDuration(buf []byte, d time.Duration) []byte {
+	var text [32]byte
+	w := len(text)
+
+	u := uint64(d)
+	neg := d < 0
+	if neg {
+		u = -u
+	}
+
+	if u < uint64(time.Second) {
+		// Less than a second is written in the largest unit below it
+		prec := 0
+		w--
+		text[w] = 's'
+		w--
+		switch {
+		case u == 0:
+			return append(buf, "0s"...)
+		case u < uint64(time.Microsecond):
+			text[w] = 'n'
+		case u < uint64(time.Millisecond):
+			prec = 3
+			w--
+			copy(text[w:], "µ")
+		default:
+			prec = 6
+			text[w] = 'm'
+		}
+		w, u = fmtFrac(text[:w], u, prec)
+		w = fmtInt(text[:w], u)
+	} else {
+		w--
+		text[w] = 's'
+		w, u = fmtFrac(text[:w], u, 9)
+		w = fmtInt(text[:w], u%60)
+		u /= 60
+		if u > 0 {
+			w--
+			text[w] = 'm'
+			w = fmtInt(text[:w], u%60)
+			u /= 60
+			if u > 0 {
+				w--
+				text[w] = 'h'
+				w = fmtInt(text[:w], u)
+			}
+		}
+	}
+
+	if neg {
+		w--
+		text[w] = '-'
+	}
+	return append(buf, text[w:]...)
+}
+
+// fmtFrac writes the fraction of v/10^prec to the end of buf, without
+// trailing zeros and omitting the decimal point when the fraction is zero.
+// It returns the index where the output starts and v/10^prec.
+func fmtFrac(buf []byte, v uint64, prec int) (int, uint64) {
+	w := len(buf)
+	printed := false
+	for range prec {
+		digit := v % 10
+		printed = printed || digit != 0
+		if printed {
+			w--
+			buf[w] = byte(digit) + '0'
+		}
+		v /= 10
+	}
+	if printed {
+		w--
+		buf[w] = '.'
+	}
+	return w, v
+}
+
+// fmtInt writes v to the end of buf and returns the index where it starts
+func fmtInt(buf []byte, v uint64) int {
+	w := len(buf)
+	if v == 0 {
+		w--
+		buf[w] = '0'
+		return w
+	}
+	for v > 0 {
+		w--
+		buf[w] = byte(v%10) + '0'
+		v /= 10
+	}
+	return w
+}
+
+// appendJSONTime appends t encoded like Time, formatting the RFC 3339
+// layouts in place
+func (e *Encoder) appendJSONTime(buf []byte, t time.Time) []byte {
+	switch e.time {
+	case config.TimeRFC3339Nano, config.TimeRFC3339:
+		layout := time.RFC3339Nano
+		if e.time == config.TimeRFC3339 {
+			layout = time.RFC3339
+		}
+		buf = append(buf, '"')
+		buf = t.AppendFormat(buf, layout)
+		return append(buf, '"')
+	case config.TimeUnix:
+		return strconv.AppendInt(buf, t.Unix(), 10)
+	case config.TimeUnixMilli:
+		return strconv.AppendInt(buf, t.UnixMilli(), 10)
+	case config.TimeUnixNano:
+		return strconv.AppendInt(buf, t.UnixNano(), 10)
+	}
+	return appendJSONAny(buf, e.Time(t))
+}
+
+func appendJSONAny(buf []byte, x any) []byte {
+	switch x := x.(type) {
+	case nil:
+		return append(buf, "null"...)
+	case string:
+		return AppendJSONString(buf, x)
+	case int64:
+		return strconv.AppendInt(buf, x, 10)
+	case uint64:
+		return strconv.AppendUint(buf, x, 10)
+	case float64:
+		return appendJSONFloat(buf, x)
+	case bool:
+		return strconv.AppendBool(buf, x)
+	}
+
+	b, err := json.Marshal(x)
+	if err != nil {
+		return AppendJSONString(buf, fmt.Sprintf("%+v", x))
+	}
+	return append(buf, b...)
+}
+
+// appendJSONFloat formats finite floats the way encoding/json does
+func appendJSONFloat(buf []byte, f float64) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf
+}
+
+const hex = "0123456789abcdef"
+
+// AppendJSONString appends s as a quoted JSON string. Invalid UTF-8 is
+// replaced with U+FFFD and, unlike encoding/json, HTML characters are kept.
+func AppendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 break JavaScript parsers
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
@@ -0,0 +1,343 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aaffriya/logger/config"
+	"github.com/aaffriya/logger/internal/diag"
+)
+
+const (
+	defaultQueueSize          = 1024
+	defaultFlushInterval      = time.Second
+	defaultBufferSize         = 64 * 1024
+	defaultSampleRate         = 10
+	defaultDropReportInterval = 10 * time.Second
+)
+
+// Level classes used to pick a backpressure policy and count drops
+const (
+	classDebug = iota
+	classInfo
+	classWarn
+	classError
+	numClasses
+)
+
+var classNames = [numClasses]string{"debug", "info", "warn", "error"}
+
+func levelClass(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return classError
+	case level >= slog.LevelWarn:
+		return classWarn
+	case level >= slog.LevelInfo:
+		return classInfo
+	default:
+		return classDebug
+	}
+}
+
+// asyncHandler queues records for a background goroutine that hands them to
+// the wrapped backend, so callers never wait on formatting or I/O unless the
+// queue is full. What happens then is decided per level by the backpressure
+// policy. Records handled after Close are written synchronously.
+type asyncHandler struct {
+	inner          LogHandler
+	interval       time.Duration
+	reportInterval time.Duration
+	policies       [numClasses]string
+	sampleRate     uint64
+	reportAttrs    []slog.Attr
+
+	mu       sync.Mutex
+	notFull  *sync.Cond
+	queue    []slog.Record
+	size     int
+	enqueued uint64
+	written  uint64        // records handed to the backend or evicted
+	progress chan struct{} // closed and replaced after every written batch
+	closed   bool
+	sampled  [numClasses]uint64
+	dropped  [numClasses]uint64
+
+	wake chan struct{}
+	done chan struct{}
+}
+
+// newAsyncHandler starts the background writer for inner. reportAttrs are
+// added to the synthetic record that reports dropped records.
+func newAsyncHandler(inner LogHandler, cfg config.AsyncConfig, reportAttrs []slog.Attr) *asyncHandler {
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	interval := cfg.FlushInterval
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+	reportInterval := cfg.Backpressure.ReportInterval
+	if reportInterval <= 0 {
+		reportInterval = defaultDropReportInterval
+	}
+	sampleRate := cfg.Backpressure.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = defaultSampleRate
+	}
+
+	h := &asyncHandler{
+		inner:          inner,
+		interval:       interval,
+		reportInterval: reportInterval,
+		sampleRate:     uint64(sampleRate),
+		reportAttrs:    reportAttrs,
+		queue:          make([]slog.Record, 0, size),
+		size:           size,
+		progress:       make(chan struct{}),
+		wake:           make(chan struct{}, 1),
+		done:           make(chan struct{}),
+	}
+	h.notFull = sync.NewCond(&h.mu)
+
+	policies := [numClasses]string{
+		classDebug: cfg.Backpressure.Debug,
+		classInfo:  cfg.Backpressure.Info,
+		classWarn:  cfg.Backpressure.Warn,
+		classError: cfg.Backpressure.Error,
+	}
+	for class, policy := range policies {
+		switch policy {
+		case config.BackpressureBlock, config.BackpressureDropNewest, config.BackpressureDropOldest, config.BackpressureSample:
+		case "":
+			policy = config.BackpressureBlock
+		default:
+			diag.Warn("unknown backpressure policy, blocking instead", "level", classNames[class], "policy", policy)
+			policy = config.BackpressureBlock
+		}
+		h.policies[class] = policy
+	}
+
+	go h.run()
+	return h
+}
+
+func (h *asyncHandler) Handle(r slog.Record) error {
+	class := levelClass(r.Level)
+
+	h.mu.Lock()
+	if len(h.queue) >= h.size && !h.closed {
+		switch h.policies[class] {
+		case config.BackpressureDropNewest:
+			h.dropped[class]++
+			h.mu.Unlock()
+			return nil
+		case config.BackpressureDropOldest:
+			if !h.evictOldest() {
+				h.dropped[class]++
+				h.mu.Unlock()
+				return nil
+			}
+		case config.BackpressureSample:
+			h.sampled[class]++
+			if h.sampled[class]%h.sampleRate != 1 && h.sampleRate > 1 {
+				h.dropped[class]++
+				h.mu.Unlock()
+				return nil
+			}
+		}
+	}
+	for len(h.queue) >= h.size && !h.closed {
+		h.notFull.Wait()
+	}
+	if h.closed {
+		h.mu.Unlock()
+		// Let the background writer finish the last batch first, so the
+		// backend never sees concurrent calls and records stay in order
+		<-h.done
+		if err := h.inner.Handle(r); err != nil {
+			return err
+		}
+		if f, ok := h.inner.(interface{ Flush() error }); ok {
+			return f.Flush()
+		}
+		return nil
+	}
+
+	h.queue = append(h.queue, r)
+	h.enqueued++
+	h.mu.Unlock()
+
+	h.signal()
+	return nil
+}
+
+// evictOldest removes the oldest queued record whose level may be dropped.
+// The caller must hold mu.
+func (h *asyncHandler) evictOldest() bool {
+	for i, r := range h.queue {
+		class := levelClass(r.Level)
+		if h.policies[class] == config.BackpressureBlock {
+			continue
+		}
+		copy(h.queue[i:], h.queue[i+1:])
+		h.queue[len(h.queue)-1] = slog.Record{}
+		h.queue = h.queue[:len(h.queue)-1]
+		h.dropped[class]++
+		h.written++
+		return true
+	}
+	return false
+}
+
+func (h *asyncHandler) signal() {
+	select {
+	case h.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (h *asyncHandler) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	reportTicker := time.NewTicker(h.reportInterval)
+	defer reportTicker.Stop()
+
+	batch := make([]slog.Record, 0, h.size)
+	dirty := false
+	for {
+		h.mu.Lock()
+		batch, h.queue = h.queue, batch[:0]
+		closed := h.closed
+		h.notFull.Broadcast()
+		h.mu.Unlock()
+
+		for _, r := range batch {
+			if err := h.inner.Handle(r); err != nil {
+				diag.Error("failed to write log record", "error", err)
+			}
+		}
+
+		if len(batch) > 0 {
+			dirty = true
+			h.mu.Lock()
+			h.written += uint64(len(batch))
+			close(h.progress)
+			h.progress = make(chan struct{})
+			h.mu.Unlock()
+			continue
+		}
+
+		if closed {
+			h.reportDropped()
+			h.flushInner()
+			return
+		}
+
+		select {
+		case <-h.wake:
+		case <-ticker.C:
+			if dirty {
+				h.flushInner()
+				dirty = false
+			}
+		case <-reportTicker.C:
+			if h.reportDropped() {
+				dirty = true
+			}
+		}
+	}
+}
+
+// reportDropped writes a synthetic record with the number of records dropped
+// per level since the last report
+func (h *asyncHandler) reportDropped() bool {
+	h.mu.Lock()
+	dropped := h.dropped
+	h.dropped = [numClasses]uint64{}
+	h.mu.Unlock()
+
+	attrs := make([]slog.Attr, 0, numClasses+1+len(h.reportAttrs))
+	var total uint64
+	for class := numClasses - 1; class >= 0; class-- {
+		if dropped[class] > 0 {
+			attrs = append(attrs, slog.Uint64("dropped_"+classNames[class], dropped[class]))
+			total += dropped[class]
+		}
+	}
+	if total == 0 {
+		return false
+	}
+
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, "logs dropped", 0)
+	r.AddAttrs(slog.Uint64("dropped", total))
+	r.AddAttrs(attrs...)
+	r.AddAttrs(h.reportAttrs...)
+	if err := h.inner.Handle(r); err != nil {
+		diag.Error("failed to report dropped log records", "dropped", total, "error", err)
+	}
+	return true
+}
+
+func (h *asyncHandler) flushInner() {
+	if f, ok := h.inner.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			diag.Error("failed to flush log records", "error", err)
+		}
+	}
+}
+
+// Flush waits until every record queued before the call has been handed to
+// the backend, then flushes the backend's buffer
+func (h *asyncHandler) Flush(ctx context.Context) error {
+	h.mu.Lock()
+	target := h.enqueued
+	for h.written < target {
+		progress := h.progress
+		h.mu.Unlock()
+
+		select {
+		case <-progress:
+		case <-h.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		h.mu.Lock()
+	}
+	h.mu.Unlock()
+
+	if f, ok := h.inner.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close drains the queue, reports pending drops, flushes the backend and
+// stops the background goroutine
+func (h *asyncHandler) Close(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	h.notFull.Broadcast()
+	h.mu.Unlock()
+	h.signal()
+
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *asyncHandler) Reopen() error {
+	if r, ok := h.inner.(interface{ Reopen() error }); ok {
+		return r.Reopen()
+	}
+	return nil
+}
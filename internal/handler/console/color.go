@@ -1,214 +1,115 @@
 package console
 
 import (
-	"net"
-	"strings"
+	"fmt"
+	"log/slog"
+
+	"github.com/aaffriya/logger/pkg/pretty"
 )
 
 const (
-	Reset          = "\033[0m"
-	Bold           = "\033[1m"
-	Italic         = "\033[3m"
-	UnderlineColor = "\033[4m"
-
-	Gray             = "\033[90m"
-	White            = "\033[97m"
-	Cyan             = "\033[36m"
-	Green            = "\033[32m"
-	Yellow           = "\033[33m"
-	Red              = "\033[31m"
-	Magenta          = "\033[35m"
-	Blue             = "\033[34m"
-	BrightBlackColor = "\033[90m"
+	Reset = "\033[0m"
 
 	NewLine = "\n"
 	Tab     = "\t"
 	Space   = " "
 )
 
-var LevelColor = map[int]string{
-	-4: Cyan,   // DEBUG
-	0:  Green,  // INFO
-	4:  Yellow, // WARN
-	8:  Red,    // ERROR
-}
-
-// Field-specific color mapping based on data type and context
-var FieldColors = map[string]string{
-	"trace_id":    Cyan + Bold,
-	"span_id":     Cyan + Bold,
-	"trace_flags": Cyan + Bold,
-	"user_id":     Blue + Bold,
-	"action":      Magenta + Bold,
-	"service":     Green + Italic,
-	"version":     Gray + Italic,
-	"error":       Red + Bold,
-	"database":    Blue,
-	"timeout":     Yellow,
-	"retry_count": Yellow,
-	"ip_address":  Cyan,
-	"user_agent":  Gray,
-	"email":       Blue,
-	"status":      Green,
-	"method":      Magenta,
-	"url":         UnderlineColor + Blue,
-	"duration":    Yellow,
-	"memory":      Cyan,
-	"cpu":         Green,
-	"disk":        Yellow,
-}
-
-// Value-based color logic
-func getValueColor(key string, value any) string {
-	// Check field-specific colors first
-	if color, exists := FieldColors[key]; exists {
-		return color
-	}
-
-	// Logic-based coloring by value type and content
-	switch v := value.(type) {
-	case string:
-		return getStringColor(key, v)
-	case int, int32, int64, float32, float64:
-		return getNumericColor(key, v)
-	case bool:
-		return getBoolColor(v)
-	case map[string]any:
-		return Magenta // Objects/maps
-	case []any, []string:
-		return Cyan // Arrays
-	default:
-		return White
-	}
-}
-
-// String value color logic
-func getStringColor(key, value string) string {
-	// Email patterns
-	if key == "email" || (len(value) > 0 && strings.Contains(value, "@")) {
-		return Blue + UnderlineColor
-	}
-
-	// URL patterns
-	if key == "url" || strings.HasPrefix(value, "http") || strings.HasPrefix(value, "https") {
-		return Blue + UnderlineColor
-	}
-
-	// IP address patterns
-	if key == "ip" || key == "ip_address" || net.ParseIP(value) != nil {
-		return Cyan + Bold
-	}
-
-	// Error/failure patterns
-	if key == "error" || key == "failure" || strings.Contains(value, "error") || strings.Contains(value, "failed") {
-		return Red + Bold
-	}
-
-	// Success patterns
-	if strings.Contains(value, "success") || strings.Contains(value, "completed") || strings.Contains(value, "ok") {
-		return Green + Bold
+// render returns the text and color of a value. A color set for the key by
+// the theme wins over the rules, which win over the color of the value type.
+func (h *prettyHandler) render(key string, v slog.Value) (string, string) {
+	v = v.Resolve()
+	value := h.textValue(v)
+	text := fmt.Sprint(value)
+	if h.config.Humanize {
+		if human, ok := h.humanize(key, v); ok {
+			text = human
+		}
 	}
 
-	// Warning patterns
-	if strings.Contains(value, "warning") || strings.Contains(value, "timeout") || strings.Contains(value, "retry") {
-		return Yellow + Bold
+	result, matched := h.rules.Apply(pretty.Field{Key: key, Value: v, Text: text})
+	if matched && result.Display != "" {
+		text = result.Display
 	}
 
-	// File paths
-	if strings.Contains(value, "/") && (strings.Contains(value, ".go") || strings.Contains(value, ".js") || strings.Contains(value, ".py")) {
-		return Gray + Italic
+	if color, exists := h.theme.fields[key]; exists {
+		return text, color
 	}
-
-	// Database related
-	if key == "database" || key == "table" || key == "query" {
-		return Blue
+	if matched && result.Color != "" {
+		return text, h.theme.resolve(result.Color)
 	}
+	return text, h.theme.typeColor(value)
+}
 
-	// Time/duration patterns
-	if key == "duration" || key == "timeout" || strings.Contains(value, "ms") || strings.Contains(value, "seconds") {
-		return Yellow
+// keyColor returns the color of an attribute key
+func (t *theme) keyColor(key string) string {
+	if color, exists := t.fields[key]; exists {
+		return color
 	}
-
-	return White
+	return t.str
 }
 
-// Numeric value color logic
-func getNumericColor(key string, value any) string {
-	// Convert to float64 for comparison
-	var numVal float64
+// typeColor returns the color of an encoded value by its type
+func (t *theme) typeColor(value any) string {
 	switch v := value.(type) {
-	case int:
-		numVal = float64(v)
-	case int32:
-		numVal = float64(v)
-	case int64:
-		numVal = float64(v)
-	case float32:
-		numVal = float64(v)
-	case float64:
-		numVal = v
-	default:
-		return White
-	}
-
-	// Status codes
-	if key == "status" || key == "code" || key == "status_code" {
-		if numVal >= 200 && numVal < 300 {
-			return Green + Bold // Success
-		} else if numVal >= 400 && numVal < 500 {
-			return Yellow + Bold // Client error
-		} else if numVal >= 500 {
-			return Red + Bold // Server error
-		}
-		return Cyan
-	}
-
-	// Performance metrics
-	if key == "duration" || key == "latency" || key == "response_time" {
-		if numVal < 100 {
-			return Green // Fast
-		} else if numVal < 1000 {
-			return Yellow // Medium
-		} else {
-			return Red // Slow
-		}
-	}
-
-	// Memory/size metrics
-	if key == "memory" || key == "size" || key == "bytes" {
-		if numVal < 1024*1024 { // < 1MB
-			return Green
-		} else if numVal < 1024*1024*100 { // < 100MB
-			return Yellow
-		} else {
-			return Red
-		}
-	}
-
-	// Retry/attempt counts
-	if key == "retry" || key == "retry_count" || key == "attempts" {
-		if numVal == 0 {
-			return Green
-		} else if numVal < 3 {
-			return Yellow
-		} else {
-			return Red
+	case int64, uint64, float64:
+		return t.number
+	case bool:
+		if v {
+			return t.boolTrue
 		}
+		return t.boolFalse
+	case map[string]any:
+		return t.object // Objects/maps
+	case []any, []string:
+		return t.array // Arrays
+	default:
+		return t.str
 	}
-
-	// Port numbers
-	if key == "port" {
-		return Cyan + Bold
-	}
-
-	// Default numeric color
-	return Cyan
 }
 
-// Boolean value color logic
-func getBoolColor(value bool) string {
-	if value {
-		return Green + Bold
-	}
-	return Red + Bold
+// resolve returns the escape sequence of a color picked by a rule, either a
+// value color of the theme or a color definition
+func (t *theme) resolve(color string) string {
+	if !t.colors {
+		return ""
+	}
+
+	switch color {
+	case pretty.ColorString:
+		return t.str
+	case pretty.ColorNumber:
+		return t.number
+	case pretty.ColorTrue:
+		return t.boolTrue
+	case pretty.ColorFalse:
+		return t.boolFalse
+	case pretty.ColorObject:
+		return t.object
+	case pretty.ColorArray:
+		return t.array
+	case pretty.ColorLink:
+		return t.link
+	case pretty.ColorAddress:
+		return t.address
+	case pretty.ColorGood:
+		return t.good
+	case pretty.ColorWarning:
+		return t.warning
+	case pretty.ColorBad:
+		return t.bad
+	case pretty.ColorPath:
+		return t.path
+	case pretty.ColorDatabase:
+		return t.database
+	case pretty.ColorDuration:
+		return t.duration
+	}
+
+	if parsed, ok := t.custom.Load(color); ok {
+		return parsed.(string)
+	}
+	parsed := parseColor(color)
+	t.custom.Store(color, parsed)
+	return parsed
 }
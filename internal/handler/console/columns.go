@@ -0,0 +1,123 @@
+package console
+
+import (
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aaffriya/logger/internal/term"
+)
+
+const (
+	levelColumnWidth  = 5  // the longest level name, ERROR
+	sourceColumnWidth = 24 // service/action
+	minWrapWidth      = 20 // values are never wrapped narrower than this
+)
+
+// buildColumnsFirstLine lays the first line out in fixed-width columns: time,
+// level, service/action and the message followed by the remaining context
+func (h *prettyHandler) buildColumnsFirstLine(r slog.Record) string {
+	var builder strings.Builder
+
+	t := h.theme
+	timestamp, level, message := h.firstLineFields(r)
+	if h.config.IncludeTimestamp && timestamp != "" {
+		builder.WriteString(paint(t.timestamp, timestamp))
+		builder.WriteString(Space)
+	}
+
+	builder.WriteString(paint(t.level(r.Level), pad(level, levelColumnWidth)))
+	builder.WriteString(Space)
+
+	builder.WriteString(h.sourceColumn(r))
+	builder.WriteString(Space)
+
+	builder.WriteString(paint(t.message, sanitize(message, messageIndent)))
+
+	h.writeContext(&builder, r, "trace_id", "user_id")
+
+	return builder.String()
+}
+
+// sourceColumn returns the service and action of r padded to
+// sourceColumnWidth, truncated when they do not fit
+func (h *prettyHandler) sourceColumn(r slog.Record) string {
+	var service, action string
+	r.Attrs(func(a slog.Attr) bool {
+		switch a.Key {
+		case "service":
+			service = escapeLine(a.Value.String())
+		case "action":
+			action = escapeLine(a.Value.String())
+		}
+		return true
+	})
+
+	text := service
+	if action != "" {
+		text += "/" + action
+	}
+
+	t := h.theme
+	if utf8.RuneCountInString(text) > sourceColumnWidth {
+		return paint(t.keyColor("service"), truncate(text, sourceColumnWidth))
+	}
+
+	padding := strings.Repeat(Space, sourceColumnWidth-utf8.RuneCountInString(text))
+	if action == "" {
+		return paint(t.keyColor("service"), service) + padding
+	}
+	return paint(t.keyColor("service"), service) + paint(t.punctuation, "/") +
+		paint(t.keyColor("action"), action) + padding
+}
+
+// wrapWidth returns the width values are wrapped to, or 0 to not wrap them
+func (h *prettyHandler) wrapWidth() int {
+	if !h.config.Columns {
+		return 0
+	}
+	return term.Width(h.out.Writer())
+}
+
+// wrapText splits text into lines of at most width runes, breaking at the
+// last space that fits when there is one. Line breaks in text are kept.
+func wrapText(text string, width int) []string {
+	var lines []string
+	for line := range strings.SplitSeq(text, NewLine) {
+		for utf8.RuneCountInString(line) > width {
+			cut := len(line)
+			n := 0
+			for i := range line {
+				if n == width {
+					cut = i
+					break
+				}
+				n++
+			}
+
+			if space := strings.LastIndexByte(line[:cut], ' '); space > 0 {
+				lines = append(lines, line[:space])
+				line = line[space+1:]
+				continue
+			}
+			lines = append(lines, line[:cut])
+			line = line[cut:]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pad returns s followed by spaces up to width runes
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(Space, width-n)
+	}
+	return s
+}
+
+// truncate shortens s to width runes, ending it with an ellipsis
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
@@ -4,79 +4,167 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aaffriya/logger/config"
+	"github.com/aaffriya/logger/internal/diag"
+	"github.com/aaffriya/logger/internal/encode"
+	filehandler "github.com/aaffriya/logger/internal/handler/file"
+	"github.com/aaffriya/logger/internal/term"
+	"github.com/aaffriya/logger/pkg/pretty"
 )
 
 type PrettyHandler interface {
 	Handle(r slog.Record) error
+	Reopen() error
 }
 
 type prettyHandler struct {
-	writer io.Writer
+	out    *filehandler.Output
+	mu     *sync.Mutex
 	config *config.PrettyConfig
+	enc    *encode.Encoder
+	theme  *theme
+	rules  *pretty.Registry
 }
 
-func NewPrettyHandler(w io.Writer, config *config.PrettyConfig) PrettyHandler {
+// NewPrettyHandler returns a console backend writing to w. file is the file
+// w writes to, reopened by Reopen, or nil when there is none.
+func NewPrettyHandler(w io.Writer, file *os.File, config *config.PrettyConfig, enc *encode.Encoder) PrettyHandler {
+	rules := config.Rules
+	if rules == nil {
+		rules = pretty.NewRegistry()
+	}
 	return &prettyHandler{
-		writer: w,
+		out:    filehandler.NewOutput(w, file),
+		mu:     &sync.Mutex{},
 		config: config,
+		enc:    enc,
+		theme:  handlerTheme(config, w),
+		rules:  rules,
+	}
+}
+
+// handlerTheme returns the theme for w, or the zero theme when colors are off
+func handlerTheme(config *config.PrettyConfig, w io.Writer) *theme {
+	if !colorEnabled(config.Color, w) {
+		return &theme{}
+	}
+	return newTheme(config.Theme)
+}
+
+// colorEnabled resolves the color mode for w, see config.ColorAuto
+func colorEnabled(mode string, w io.Writer) bool {
+	switch mode {
+	case config.ColorAlways:
+		return true
+	case config.ColorNever:
+		return false
+	case "", config.ColorAuto:
+	default:
+		diag.Warn("unknown color mode, detecting it from the writer", "color", mode)
 	}
+	return term.ColorEnabled(w)
+}
+
+// write writes one rendered record logged at t. Writes are serialized since
+// writers such as a RotatingFile are not safe for concurrent use, and go to
+// the file t belongs to like those of the file backends.
+func (h *prettyHandler) write(t time.Time, p []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.out.WriteRecord(t, p)
+}
+
+// Reopen reopens the file written to, so console formats written to a file
+// follow logrotate too
+func (h *prettyHandler) Reopen() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.out.Reopen()
 }
 
 func (h *prettyHandler) buildLogFirstLine(r slog.Record) string {
+	if h.config.Columns {
+		return h.buildColumnsFirstLine(r)
+	}
+
 	var builder strings.Builder
 
-	if h.config.IncludeTimestamp {
-		builder.WriteString(Gray)
-		builder.WriteString(r.Time.Format("2006-01-02 15:04:05.000"))
-		builder.WriteString(Reset)
+	t := h.theme
+	timestamp, level, message := h.firstLineFields(r)
+	if h.config.IncludeTimestamp && timestamp != "" {
+		builder.WriteString(paint(t.timestamp, timestamp))
 		builder.WriteString(Space)
 	}
 
-	levelColor := LevelColor[int(r.Level.Level())]
 	builder.WriteString("[")
-	builder.WriteString(levelColor)
-	builder.WriteString(Bold)
-	builder.WriteString(r.Level.String())
-	builder.WriteString(Reset)
+	builder.WriteString(paint(t.level(r.Level), level))
 	builder.WriteString("] ")
 
-	builder.WriteString(Bold)
-	builder.WriteString(White)
-	builder.WriteString(r.Message)
-	builder.WriteString(Reset)
+	builder.WriteString(paint(t.message, sanitize(message, messageIndent)))
 
-	contextAttrs := make(map[string]any)
+	h.writeContext(&builder, r, "trace_id", "user_id", "action")
+
+	return builder.String()
+}
+
+// firstLineFields returns the timestamp, level and message of the first
+// line, written in place of the record fields when ReplaceAttr gave them
+// values a record cannot hold. The timestamp is empty when there is none.
+func (h *prettyHandler) firstLineFields(r slog.Record) (timestamp, level, message string) {
+	builtins := encode.BuiltinsOf(r)
+	switch {
+	case builtins.Time != nil:
+		timestamp = escapeLine(h.enc.String(*builtins.Time))
+	case !r.Time.IsZero():
+		timestamp = r.Time.Format("2006-01-02 15:04:05.000")
+	}
+
+	level = r.Level.String()
+	if builtins.Level != nil {
+		level = escapeLine(h.enc.String(*builtins.Level))
+	}
+
+	message = r.Message
+	if builtins.Message != nil {
+		message = h.enc.String(*builtins.Message)
+	}
+	return timestamp, level, message
+}
+
+// writeContext writes the values of the context keys found in r, in the order
+// given, after a separator
+func (h *prettyHandler) writeContext(builder *strings.Builder, r slog.Record, order ...string) {
+	t := h.theme
+	contextAttrs := make(map[string]slog.Value)
 	r.Attrs(func(a slog.Attr) bool {
-		if a.Key == "trace_id" || a.Key == "user_id" || a.Key == "action" {
-			contextAttrs[a.Key] = a.Value.Any()
+		if slices.Contains(order, a.Key) {
+			contextAttrs[a.Key] = a.Value
 		}
 		return true
 	})
 
 	if len(contextAttrs) > 0 {
 		builder.WriteString(Space)
-		builder.WriteString(Gray)
-		builder.WriteString("|")
-		builder.WriteString(Reset)
+		builder.WriteString(paint(t.punctuation, "|"))
 		builder.WriteString(Space)
 
-		order := []string{"trace_id", "user_id", "action"}
 		parts := make([]string, 0, len(order))
 
 		for _, key := range order {
 			if value, exists := contextAttrs[key]; exists {
-				color := getValueColor(key, value)
-				parts = append(parts, color+fmt.Sprintf("%v", value)+Reset)
+				text, color := h.render(key, value)
+				parts = append(parts, paint(color, sanitize(text, messageIndent)))
 			}
 		}
 
-		builder.WriteString(joinStrings(parts, Space+Gray+"•"+Reset+Space))
+		builder.WriteString(joinStrings(parts, Space+paint(t.punctuation, "•")+Space))
 	}
-
-	return builder.String()
 }
 
 func (h *prettyHandler) buildTraceSection(trace []string) string {
@@ -85,17 +173,15 @@ func (h *prettyHandler) buildTraceSection(trace []string) string {
 	}
 
 	var builder strings.Builder
-	builder.WriteString(Gray)
-	builder.WriteString("Stack Trace:")
-	builder.WriteString(Reset)
+	builder.WriteString(paint(h.theme.label, "Stack Trace:"))
 	builder.WriteString(NewLine)
 
 	for i, s := range trace {
-		traceColor := Gray + Italic
+		traceColor := h.theme.trace
 		if strings.Contains(s, ".go:") {
-			traceColor = Cyan + Italic
+			traceColor = h.theme.traceFile
 		}
-		builder.WriteString(fmt.Sprintf("  %s%d. %s%s%s", Gray, i+1, traceColor, s, Reset+NewLine))
+		builder.WriteString(fmt.Sprintf("  %s %s%s", paint(h.theme.punctuation, fmt.Sprintf("%d.", i+1)), paint(traceColor, escapeLine(s)), NewLine))
 	}
 	return builder.String()
 }
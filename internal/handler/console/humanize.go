@@ -0,0 +1,194 @@
+package console
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	groupDigitsFrom = 100000 // smaller numbers, such as ports and status codes, stay as they are
+	maxHumanRunes   = 100    // longer strings are truncated
+	maxHumanItems   = 10     // longer slices are truncated
+)
+
+// byteKeys hold byte counts
+var byteKeys = []string{"size", "bytes", "memory"}
+
+// humanize returns v rendered for people rather than machines, or false when
+// it has no human form and the encoded text is shown as is
+func (h *prettyHandler) humanize(key string, v slog.Value) (string, bool) {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return humanDuration(v.Duration()), true
+	case slog.KindTime:
+		return humanTime(v.Time(), time.Now()), true
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64:
+		return humanNumber(key, v)
+	case slog.KindString:
+		return truncateText(v.String())
+	case slog.KindAny:
+		return h.truncateSlice(v.Any())
+	}
+	return "", false
+}
+
+// humanDuration rounds d to the precision worth reading, such as 1.2s or
+// 3m25s
+func humanDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+
+	switch {
+	case d < time.Microsecond:
+		return sign + d.String()
+	case d < time.Millisecond:
+		return sign + decimal(float64(d)/float64(time.Microsecond)) + "µs"
+	case d < time.Second:
+		return sign + decimal(float64(d)/float64(time.Millisecond)) + "ms"
+	case d < time.Minute:
+		return sign + decimal(d.Seconds()) + "s"
+	case d < time.Hour:
+		d = d.Round(time.Second)
+		return sign + fmt.Sprintf("%dm%ds", d/time.Minute, d%time.Minute/time.Second)
+	}
+	d = d.Round(time.Minute)
+	return sign + fmt.Sprintf("%dh%dm", d/time.Hour, d%time.Hour/time.Minute)
+}
+
+// decimal formats f with at most one decimal
+func decimal(f float64) string {
+	return strconv.FormatFloat(math.Round(f*10)/10, 'f', -1, 64)
+}
+
+// humanTime returns t relative to now, such as 3m ago or in 2h
+func humanTime(t, now time.Time) string {
+	d := now.Sub(t)
+	if d > -time.Second && d < time.Second {
+		return "just now"
+	}
+
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var ago string
+	switch {
+	case d < time.Minute:
+		ago = strconv.Itoa(int(d/time.Second)) + "s"
+	case d < time.Hour:
+		ago = strconv.Itoa(int(d/time.Minute)) + "m"
+	case d < 24*time.Hour:
+		ago = strconv.Itoa(int(d/time.Hour)) + "h"
+	default:
+		ago = strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	}
+
+	if future {
+		return "in " + ago
+	}
+	return ago + " ago"
+}
+
+// humanNumber returns byte counts in binary units and large numbers with
+// their digits grouped
+func humanNumber(key string, v slog.Value) (string, bool) {
+	var f float64
+	var text string
+	switch v.Kind() {
+	case slog.KindInt64:
+		f, text = float64(v.Int64()), strconv.FormatInt(v.Int64(), 10)
+	case slog.KindUint64:
+		f, text = float64(v.Uint64()), strconv.FormatUint(v.Uint64(), 10)
+	default:
+		f = v.Float64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", false
+		}
+		text = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	if slices.Contains(byteKeys, key) {
+		return humanBytes(f), true
+	}
+	if math.Abs(f) < groupDigitsFrom {
+		return "", false
+	}
+	return groupDigits(text), true
+}
+
+// humanBytes formats n bytes in binary units, such as 3.4 MiB
+func humanBytes(n float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	i := 0
+	for math.Abs(n) >= 1024 && i < len(units)-1 {
+		n /= 1024
+		i++
+	}
+	if i == 0 {
+		return strconv.FormatFloat(n, 'f', -1, 64) + " B"
+	}
+	return decimal(n) + " " + units[i]
+}
+
+// groupDigits separates the thousands of the integer part of a formatted
+// number with commas
+func groupDigits(text string) string {
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	integer, fraction, _ := strings.Cut(text, ".")
+
+	var builder strings.Builder
+	builder.WriteString(sign)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteRune(digit)
+	}
+	if fraction != "" {
+		builder.WriteString("." + fraction)
+	}
+	return builder.String()
+}
+
+// truncateText shortens strings longer than maxHumanRunes
+func truncateText(s string) (string, bool) {
+	n := utf8.RuneCountInString(s)
+	if n <= maxHumanRunes {
+		return "", false
+	}
+	return string([]rune(s)[:maxHumanRunes]) + "… " + moreMarker(n-maxHumanRunes), true
+}
+
+// truncateSlice shows the first maxHumanItems elements of longer slices
+func (h *prettyHandler) truncateSlice(x any) (string, bool) {
+	rv := reflect.ValueOf(x)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", false
+	}
+	if rv.Type().Elem().Kind() == reflect.Uint8 || rv.Len() <= maxHumanItems {
+		return "", false
+	}
+
+	items := make([]string, maxHumanItems)
+	for i := range items {
+		items[i] = fmt.Sprint(h.enc.Any(rv.Index(i).Interface()))
+	}
+	return "[" + strings.Join(items, " ") + " …] " + moreMarker(rv.Len()-maxHumanItems), true
+}
+
+func moreMarker(n int) string {
+	return "(+" + strconv.Itoa(n) + " more)"
+}
@@ -1,20 +1,25 @@
 package console
 
 import (
+	"bytes"
 	"encoding/json"
 	"log/slog"
+
+	"github.com/aaffriya/logger/internal/encode"
 )
 
 func (h *prettyHandler) Json(r slog.Record) error {
 	logLine := h.buildLogFirstLine(r)
 
-	logData := map[string]any{}
+	// Attributes are encoded in order, encoding/json would sort map keys
+	logData := []byte{'{'}
 	var trace []string
 
-	r.Attrs(func(a slog.Attr) bool {
-		if a.Key == "service" || a.Key == "version" ||
+	for a := range r.Attrs {
+		// An empty key carries the Builtins of the first line
+		if a.Key == "" || a.Key == "service" || a.Key == "version" ||
 			a.Key == "trace_id" || a.Key == "span_id" || a.Key == "trace_flags" || a.Key == "user_id" || a.Key == "action" {
-			return true
+			continue
 		}
 
 		switch a.Key {
@@ -23,10 +28,19 @@ func (h *prettyHandler) Json(r slog.Record) error {
 				trace = traceVal
 			}
 		default:
-			logData[a.Key] = h.convertValueForJSON(a.Value.Any())
+			if len(logData) > 1 {
+				logData = append(logData, ',')
+			}
+			logData = encode.AppendJSONString(logData, a.Key)
+			logData = append(logData, ':')
+			// Errors nested in maps and slices are shown by their message too
+			v := a.Value.Resolve()
+			if v.Kind() == slog.KindAny {
+				v = slog.AnyValue(h.convertValueForJSON(v.Any()))
+			}
+			logData = h.enc.AppendJSON(logData, v)
 		}
-		return true
-	})
+	}
 
 	if len(trace) > 0 {
 		logLine += NewLine + h.buildTraceSection(trace)
@@ -34,18 +48,18 @@ func (h *prettyHandler) Json(r slog.Record) error {
 
 	logLineByte := []byte(logLine)
 
-	if len(logData) > 0 {
-		logLineByte = append(logLineByte, []byte(NewLine+Gray+"Data:"+Reset+NewLine)...)
-		jsonBytes, _ := json.MarshalIndent(logData, "", "  ")
+	if len(logData) > 1 {
+		logData = append(logData, '}')
+		logLineByte = append(logLineByte, []byte(NewLine+paint(h.theme.label, "Data:")+NewLine)...)
+		var jsonBytes bytes.Buffer
+		_ = json.Indent(&jsonBytes, logData, "", "  ")
 
-		coloredJSON := applyJSONSyntaxHighlighting(string(jsonBytes))
-		logLineByte = append(logLineByte, []byte(coloredJSON)...)
+		logLineByte = h.theme.appendHighlightedJSON(logLineByte, jsonBytes.Bytes())
 	}
 
 	logLineByte = append(logLineByte, byte('\n'))
 
-	_, err := h.writer.Write(logLineByte)
-	return err
+	return h.write(r.Time, logLineByte)
 }
 
 // convertValueForJSON recursively converts values to be JSON-serializable
@@ -0,0 +1,205 @@
+package console
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aaffriya/logger/config"
+	"github.com/aaffriya/logger/internal/encode"
+)
+
+// PrettyJSONWriter renders NDJSON log records written to it, one JSON object
+// per line, in the layout of the pretty handler. Writes may split or join
+// lines anywhere; a line is rendered once its newline arrives, or on Close.
+// Lines that are not JSON objects are passed through escaped.
+type PrettyJSONWriter struct {
+	mu      sync.Mutex
+	handler *prettyHandler
+	line    []byte
+}
+
+// NewPrettyJSONWriter returns a writer rendering records to w with cfg
+func NewPrettyJSONWriter(w io.Writer, cfg *config.PrettyConfig) *PrettyJSONWriter {
+	enc := encode.New(config.EncodingConfig{})
+	return &PrettyJSONWriter{handler: NewPrettyHandler(w, nil, cfg, enc).(*prettyHandler)}
+}
+
+func (w *PrettyJSONWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.line = append(w.line, p...)
+	rest := w.line
+	var err error
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			break
+		}
+		err = errors.Join(err, w.render(rest[:i]))
+		rest = rest[i+1:]
+	}
+	w.line = append(w.line[:0], rest...)
+	return len(p), err
+}
+
+// Close renders a last line written without a newline. The underlying
+// writer is not closed.
+func (w *PrettyJSONWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.line) == 0 {
+		return nil
+	}
+	err := w.render(w.line)
+	w.line = w.line[:0]
+	return err
+}
+
+// render writes one line, as a record when it holds a JSON object
+func (w *PrettyJSONWriter) render(line []byte) error {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return nil
+	}
+
+	r, err := decodeRecord(line)
+	if err != nil {
+		// Lines that are not records have no time of their own
+		return w.handler.write(time.Now(), []byte(sanitize(string(line), "")+NewLine))
+	}
+	return w.handler.Handle(r)
+}
+
+// decodeRecord decodes a record written by a JSON handler, reading the time,
+// level and message under the keys of this logger or of slog. Other members
+// become attributes in the order they appear.
+func decodeRecord(line []byte) (slog.Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+
+	v, err := decodeValue(dec)
+	if err != nil {
+		return slog.Record{}, err
+	}
+	if v.Kind() != slog.KindGroup {
+		return slog.Record{}, errors.New("not a JSON object")
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return slog.Record{}, errors.New("data after the JSON object")
+	}
+
+	var r slog.Record
+	r.Level = slog.LevelInfo
+	attrs := make([]slog.Attr, 0, len(v.Group()))
+	for _, a := range v.Group() {
+		switch a.Key {
+		case "timestamp", slog.TimeKey:
+			if t, err := time.Parse(time.RFC3339Nano, a.Value.String()); err == nil {
+				r.Time = t
+				continue
+			}
+		case slog.LevelKey:
+			if a.Value.Kind() == slog.KindString && r.Level.UnmarshalText([]byte(a.Value.String())) == nil {
+				continue
+			}
+		case "message", slog.MessageKey:
+			if a.Value.Kind() == slog.KindString {
+				r.Message = a.Value.String()
+				continue
+			}
+		case "trace":
+			if trace, ok := stringSlice(a.Value); ok {
+				a.Value = slog.AnyValue(trace)
+			}
+		}
+		attrs = append(attrs, a)
+	}
+	r.AddAttrs(attrs...)
+	return r, nil
+}
+
+// decodeValue reads the next JSON value of dec, keeping object members in
+// order as a group
+func decodeValue(dec *json.Decoder) (slog.Value, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return slog.Value{}, err
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '{' {
+			var attrs []slog.Attr
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return slog.Value{}, err
+				}
+				v, err := decodeValue(dec)
+				if err != nil {
+					return slog.Value{}, err
+				}
+				attrs = append(attrs, slog.Attr{Key: key.(string), Value: v})
+			}
+			_, err := dec.Token()
+			return slog.GroupValue(attrs...), err
+		}
+
+		items := []any{}
+		for dec.More() {
+			v, err := decodeValue(dec)
+			if err != nil {
+				return slog.Value{}, err
+			}
+			items = append(items, jsonAny(v))
+		}
+		_, err := dec.Token()
+		return slog.AnyValue(items), err
+	case json.Number:
+		if n, err := token.Int64(); err == nil {
+			return slog.Int64Value(n), nil
+		}
+		f, err := token.Float64()
+		return slog.Float64Value(f), err
+	case string:
+		return slog.StringValue(token), nil
+	case bool:
+		return slog.BoolValue(token), nil
+	}
+	return slog.AnyValue(nil), nil
+}
+
+// jsonAny returns v as the value json.Unmarshal would have decoded, for
+// array elements
+func jsonAny(v slog.Value) any {
+	if v.Kind() != slog.KindGroup {
+		return v.Any()
+	}
+	object := make(map[string]any, len(v.Group()))
+	for _, a := range v.Group() {
+		object[a.Key] = jsonAny(a.Value)
+	}
+	return object
+}
+
+// stringSlice returns an array of strings as a []string
+func stringSlice(v slog.Value) ([]string, bool) {
+	items, ok := v.Any().([]any)
+	if !ok {
+		return nil, false
+	}
+	strs := make([]string, len(items))
+	for i, item := range items {
+		if strs[i], ok = item.(string); !ok {
+			return nil, false
+		}
+	}
+	return strs, true
+}
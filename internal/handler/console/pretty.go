@@ -1,122 +1,110 @@
 package console
 
 import (
-	"encoding/json"
-	"io"
 	"strings"
+	"unicode/utf8"
 )
 
-type PrettyJSONWriter struct {
-	writer io.Writer
-}
+// appendHighlightedJSON appends src, a JSON document, to dst with each token
+// in its theme color. It reads src once, token by token. Strings and anything
+// that is not JSON are escaped like other untrusted console text.
+func (t *theme) appendHighlightedJSON(dst, src []byte) []byte {
+	for i := 0; i < len(src); {
+		var end int
+		color := ""
 
-func NewPrettyJSONWriter(w io.Writer) *PrettyJSONWriter {
-	return &PrettyJSONWriter{writer: w}
-}
+		switch c := src[i]; {
+		case c == ' ' || c == '\n' || c == '\t' || c == '\r':
+			dst = append(dst, c)
+			i++
+			continue
+		case c == '{' || c == '}' || c == '[' || c == ']':
+			end, color = i+1, t.jsonBrace
+		case c == ':':
+			end, color = i+1, t.jsonColon
+		case c == ',':
+			end, color = i+1, t.jsonComma
+		case c == '"':
+			end, color = stringEnd(src, i), t.jsonString
+			if nextToken(src, end) == ':' {
+				color = t.jsonKey
+			}
+		case c == '-' || isDigit(c):
+			end, color = numberEnd(src, i), t.jsonNumber
+		default:
+			end = wordEnd(src, i)
+			switch string(src[i:end]) {
+			case "true", "false":
+				color = t.jsonBool
+			case "null":
+				color = t.jsonNull
+			}
+		}
 
-func (w *PrettyJSONWriter) Write(p []byte) (n int, err error) {
-	var jsonData any
-	if err := json.Unmarshal(p, &jsonData); err != nil {
-		return w.writer.Write(p)
+		dst = append(dst, color...)
+		dst = appendSanitized(dst, src[i:end])
+		if color != "" {
+			dst = append(dst, Reset...)
+		}
+		i = end
 	}
+	return dst
+}
 
-	prettyBytes, err := json.MarshalIndent(jsonData, "", "  ")
-	if err != nil {
-		return w.writer.Write(p)
+// appendSanitized appends token to dst, sanitized unless it is printable
+// ASCII
+func appendSanitized(dst, token []byte) []byte {
+	for _, c := range token {
+		if c < ' ' || c >= utf8.RuneSelf-1 {
+			return append(dst, sanitize(string(token), "")...)
+		}
 	}
-
-	prettyBytes = append(prettyBytes, '\n')
-
-	return w.writer.Write(prettyBytes)
+	return append(dst, token...)
 }
 
-// Apply basic JSON syntax highlighting
-func applyJSONSyntaxHighlighting(jsonStr string) string {
-	result := ""
-	inString := false
-	inKey := false
-	escaped := false
-
-	for i, char := range jsonStr {
-		switch char {
-		case '"':
-			if !escaped {
-				if !inString {
-					inString = true
-					// Determine if this is a key (followed by :) or value
-					colonIndex := findNextNonWhitespace(jsonStr, i+1)
-					if colonIndex != -1 && jsonStr[colonIndex] == ':' {
-						inKey = true
-						result += Cyan + Bold + string(char) // Key color
-					} else {
-						result += Green + string(char) // String value color
-					}
-				} else {
-					if inKey {
-						result += string(char) + Reset
-						inKey = false
-					} else {
-						result += string(char) + Reset
-					}
-					inString = false
-				}
-			} else {
-				result += string(char)
-			}
-			escaped = false
+// stringEnd returns the index after the string starting at i, or the end of
+// src when it is not terminated
+func stringEnd(src []byte, i int) int {
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
 		case '\\':
-			result += string(char)
-			escaped = !escaped
-		case ':':
-			if !inString {
-				result += Yellow + string(char) + Reset
-			} else {
-				result += string(char)
-			}
-			escaped = false
-		case '{', '}', '[', ']':
-			if !inString {
-				result += Magenta + Bold + string(char) + Reset
-			} else {
-				result += string(char)
-			}
-			escaped = false
-		case ',':
-			if !inString {
-				result += Gray + string(char) + Reset
-			} else {
-				result += string(char)
-			}
-			escaped = false
-		default:
-			// Numbers
-			if !inString && char >= '0' && char <= '9' {
-				result += Cyan + string(char) + Reset
-			} else if !inString && (char == 't' || char == 'f' || char == 'n') {
-				// Boolean and null values
-				if strings.HasPrefix(jsonStr[i:], "true") || strings.HasPrefix(jsonStr[i:], "false") {
-					result += Yellow + Bold + string(char) + Reset
-				} else if strings.HasPrefix(jsonStr[i:], "null") {
-					result += Red + string(char) + Reset
-				} else {
-					result += string(char)
-				}
-			} else {
-				result += string(char)
-			}
-			escaped = false
+			j++
+		case '"':
+			return j + 1
 		}
 	}
+	return len(src)
+}
 
-	return result
+// numberEnd returns the index after the number starting at i, including its
+// sign, fraction and exponent
+func numberEnd(src []byte, i int) int {
+	j := i + 1
+	for j < len(src) && (isDigit(src[j]) || src[j] == '.' || src[j] == 'e' || src[j] == 'E' || src[j] == '+' || src[j] == '-') {
+		j++
+	}
+	return j
+}
+
+// wordEnd returns the index of the first byte after i that ends a literal
+func wordEnd(src []byte, i int) int {
+	j := i + 1
+	for j < len(src) && !strings.ContainsRune(" \t\r\n{}[]:,\"", rune(src[j])) {
+		j++
+	}
+	return j
 }
 
-// Helper to find next non-whitespace character
-func findNextNonWhitespace(s string, start int) int {
-	for i := start; i < len(s); i++ {
-		if s[i] != ' ' && s[i] != '\t' && s[i] != '\n' && s[i] != '\r' {
-			return i
+// nextToken returns the first byte after i that is not whitespace, or 0
+func nextToken(src []byte, i int) byte {
+	for ; i < len(src); i++ {
+		if c := src[i]; c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return c
 		}
 	}
-	return -1
+	return 0
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
 }
@@ -0,0 +1,42 @@
+package console
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+)
+
+// highlightBenchmarkDocument returns an indented JSON document of about 1 MB
+// with every token type
+func highlightBenchmarkDocument(b *testing.B) []byte {
+	var doc bytes.Buffer
+	doc.WriteString(`{"rows":[`)
+	for i := 0; doc.Len() < 1<<20; i++ {
+		if i > 0 {
+			doc.WriteByte(',')
+		}
+		fmt.Fprintf(&doc, `{"id":%d,"name":"row \"%d\"","score":-1.5e-3,"active":%t,"note":null,"tags":["a","b"]}`, i, i, i%2 == 0)
+	}
+	doc.WriteString(`]}`)
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, doc.Bytes(), "", "  "); err != nil {
+		b.Fatal(err)
+	}
+	return indented.Bytes()
+}
+
+func BenchmarkAppendHighlightedJSON(b *testing.B) {
+	src := highlightBenchmarkDocument(b)
+	t := newTheme(config.ThemeConfig{})
+	dst := make([]byte, 0, 4*len(src))
+
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	for b.Loop() {
+		dst = t.appendHighlightedJSON(dst[:0], src)
+	}
+}
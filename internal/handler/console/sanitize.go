@@ -0,0 +1,84 @@
+package console
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	messageIndent = "    " // continuation lines of messages and context values
+	zeroWidthJoin = '\u200d'
+)
+
+// sanitize makes untrusted text safe to write to a terminal: newlines are
+// followed by indent, so a value can never start a line that looks like a
+// record, and control characters, escape sequences, invalid UTF-8 and other
+// invisible characters are escaped.
+func sanitize(s, indent string) string {
+	return escape(s, NewLine+indent)
+}
+
+// escapeLine is sanitize for text that has to stay on one line, such as keys
+func escapeLine(s string) string {
+	return escape(s, `\n`)
+}
+
+func escape(s, newline string) string {
+	if isSafe(s) {
+		return s
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(s) + 8)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			builder.WriteString(`\x`)
+			builder.WriteString(strconv.FormatUint(uint64(s[i])|0x100, 16)[1:])
+		case r == '\n':
+			builder.WriteString(newline)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case safeRune(r):
+			builder.WriteRune(r)
+		case r < utf8.RuneSelf:
+			builder.WriteString(`\x`)
+			builder.WriteString(strconv.FormatUint(uint64(r)|0x100, 16)[1:])
+		case r > 0xffff:
+			builder.WriteString(`\U`)
+			builder.WriteString(strconv.FormatUint(uint64(r)|0x100000000, 16)[1:])
+		default:
+			builder.WriteString(`\u`)
+			builder.WriteString(strconv.FormatUint(uint64(r)|0x10000, 16)[1:])
+		}
+		i += size
+	}
+	return builder.String()
+}
+
+// isSafe reports whether s can be written unchanged, checking the common
+// printable ASCII case byte by byte
+func isSafe(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < ' ' || c >= utf8.RuneSelf-1 {
+			return !strings.ContainsFunc(s, func(r rune) bool { return !safeRune(r) }) && utf8.ValidString(s)
+		}
+	}
+	return true
+}
+
+// safeRune reports whether r is printed as itself: letters, marks, numbers,
+// punctuation, symbols and spaces, plus the joiner of emoji sequences. Line
+// and paragraph separators, bidirectional overrides and other format
+// characters are not.
+func safeRune(r rune) bool {
+	if r == utf8.RuneError {
+		return false
+	}
+	return r == zeroWidthJoin || unicode.IsPrint(r) || unicode.Is(unicode.Zs, r)
+}
@@ -3,7 +3,10 @@ package console
 import (
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 func (h *prettyHandler) Text(r slog.Record) error {
@@ -12,47 +15,141 @@ func (h *prettyHandler) Text(r slog.Record) error {
 	// Build the first line
 	builder.WriteString(h.buildLogFirstLine(r))
 
-	attrs := make(map[string]any)
+	attrs, trace := h.textAttrs(r)
+
+	if h.config.Compact {
+		for _, a := range attrs {
+			h.writeCompactAttr(&builder, "", a)
+		}
+		builder.WriteString(NewLine)
+		builder.WriteString(h.buildTraceSection(trace))
+		return h.write(r.Time, []byte(builder.String()))
+	}
+
+	if len(trace) > 0 {
+		builder.WriteString(NewLine)
+		builder.WriteString(h.buildTraceSection(trace))
+	}
+
+	if len(attrs) > 0 {
+		builder.WriteString(NewLine)
+		width := h.wrapWidth()
+		for _, a := range attrs {
+			h.writeTextAttr(&builder, a, 1, width)
+		}
+	} else if len(trace) == 0 {
+		builder.WriteString(NewLine)
+	}
+
+	return h.write(r.Time, []byte(builder.String()))
+}
+
+// textAttrs returns the attributes listed after the first line and the stack
+// trace
+func (h *prettyHandler) textAttrs(r slog.Record) ([]slog.Attr, []string) {
+	var attrs []slog.Attr
 	var trace []string
 
-	r.Attrs(func(a slog.Attr) bool {
-		if a.Key == "service" || a.Key == "version" ||
+	for a := range r.Attrs {
+		// An empty key carries the Builtins of the first line
+		if a.Key == "" || a.Key == "service" || a.Key == "version" ||
 			a.Key == "trace_id" || a.Key == "span_id" || a.Key == "trace_flags" || a.Key == "user_id" || a.Key == "action" {
-			return true
+			continue
 		}
 
 		if a.Key == "trace" {
 			if traceVal, ok := a.Value.Any().([]string); ok {
 				trace = traceVal
 			}
-			return true
+			continue
 		}
 
-		attrs[a.Key] = a.Value.Any()
-		return true
-	})
+		attrs = append(attrs, a)
+	}
+	return attrs, trace
+}
 
-	if len(trace) > 0 {
-		builder.WriteString(NewLine)
-		builder.WriteString(h.buildTraceSection(trace))
+// textValue returns the value printed for v; groups stay []slog.Attr so
+// they can be rendered as a tree
+func (h *prettyHandler) textValue(v slog.Value) any {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		return v.Group()
+	case slog.KindAny:
+		if _, ok := v.Any().(*slog.Source); ok {
+			return h.enc.String(v)
+		}
 	}
+	return h.enc.Value(v)
+}
 
-	if len(attrs) > 0 {
-		builder.WriteString(NewLine)
-		for key, value := range attrs {
-			keyColor := getValueColor(key, key)
-			valueColor := getValueColor(key, value)
+// writeTextAttr writes one key=value line at the given depth, or a group
+// name followed by its members indented one level deeper. With a positive
+// width, values are wrapped to it and continued under their first line.
+func (h *prettyHandler) writeTextAttr(builder *strings.Builder, a slog.Attr, depth, width int) {
+	indent := strings.Repeat("  ", depth)
 
-			builder.WriteString(fmt.Sprintf("  %s%s%s%s=%s%v%s%s",
-				Gray, keyColor, key, Reset,
-				valueColor, value, Reset, NewLine))
+	if group, ok := h.textValue(a.Value).([]slog.Attr); ok {
+		builder.WriteString(fmt.Sprintf("%s%s:%s",
+			indent, paint(h.theme.group, escapeLine(a.Key)), NewLine))
+		for _, member := range group {
+			h.writeTextAttr(builder, member, depth+1, width)
 		}
-	} else if len(trace) == 0 {
-		builder.WriteString(NewLine)
+		return
+	}
+
+	text, color := h.render(a.Key, a.Value)
+	key := escapeLine(a.Key)
+	hanging := len(indent) + utf8.RuneCountInString(key) + 1
+
+	// Embedded newlines continue under the first line of the value
+	var lines []string
+	if width > 0 {
+		lines = wrapText(sanitize(text, ""), max(width-hanging, minWrapWidth))
+	} else {
+		lines = strings.Split(sanitize(text, ""), NewLine)
+	}
+	for i, line := range lines {
+		lines[i] = paint(color, line)
 	}
 
-	_, err := h.writer.Write([]byte(builder.String()))
-	return err
+	builder.WriteString(fmt.Sprintf("%s%s=%s%s",
+		indent, paint(h.theme.keyColor(a.Key), key),
+		strings.Join(lines, NewLine+strings.Repeat(Space, hanging)), NewLine))
+}
+
+// writeCompactAttr writes a space and key=value, flattening groups into
+// dotted keys. Values that would not read as a single token, or that hold
+// characters unsafe for a terminal, are quoted and escaped.
+func (h *prettyHandler) writeCompactAttr(builder *strings.Builder, prefix string, a slog.Attr) {
+	key := prefix + a.Key
+
+	if group, ok := h.textValue(a.Value).([]slog.Attr); ok {
+		if a.Key != "" {
+			prefix = key + "."
+		}
+		for _, member := range group {
+			h.writeCompactAttr(builder, prefix, member)
+		}
+		return
+	}
+
+	text, color := h.render(key, a.Value)
+	if needsQuote(text) {
+		text = strconv.Quote(text)
+	}
+
+	builder.WriteString(Space)
+	builder.WriteString(paint(h.theme.keyColor(key), escapeLine(key)))
+	builder.WriteString("=")
+	builder.WriteString(paint(color, text))
+}
+
+func needsQuote(text string) bool {
+	return text == "" || strings.ContainsFunc(text, func(r rune) bool {
+		return r == '"' || unicode.IsSpace(r) || !safeRune(r)
+	})
 }
 
 func joinStrings(strs []string, separator string) string {
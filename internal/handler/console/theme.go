@@ -0,0 +1,265 @@
+package console
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aaffriya/logger/config"
+	"github.com/aaffriya/logger/internal/diag"
+)
+
+// theme holds the escape sequences of one handler. The zero theme writes no
+// colors.
+type theme struct {
+	colors bool
+	custom sync.Map // color definitions returned by rules, parsed once
+
+	timestamp, message, punctuation, label, group, trace, traceFile string
+
+	debug, info, warn, error string
+
+	str, number, boolTrue, boolFalse, object, array string
+	link, address, good, warning, bad               string
+	path, database, duration                        string
+
+	jsonKey, jsonString, jsonNumber, jsonBool, jsonNull string
+	jsonBrace, jsonColon, jsonComma                     string
+
+	fields map[string]string
+}
+
+var builtinThemes = map[string]config.ThemeConfig{
+	config.ThemeDark: {
+		Timestamp:   "gray",
+		Message:     "bold bright-white",
+		Punctuation: "gray",
+		Label:       "gray",
+		Group:       "bold magenta",
+		Trace:       "italic gray",
+		TraceFile:   "italic cyan",
+		Levels:      config.LevelColors{Debug: "bold cyan", Info: "bold green", Warn: "bold yellow", Error: "bold red"},
+		Values: config.ValueColors{
+			String: "bright-white", Number: "cyan", True: "bold green", False: "bold red",
+			Object: "magenta", Array: "cyan", Link: "underline blue", Address: "bold cyan",
+			Good: "bold green", Warning: "bold yellow", Bad: "bold red",
+			Path: "italic gray", Database: "blue", Duration: "yellow",
+		},
+		JSON: config.JSONColors{
+			Key: "bold cyan", String: "green", Number: "cyan", Bool: "bold yellow",
+			Null: "red", Brace: "bold magenta", Colon: "yellow", Comma: "gray",
+		},
+		Fields: map[string]string{
+			"trace_id":    "bold cyan",
+			"span_id":     "bold cyan",
+			"trace_flags": "bold cyan",
+			"user_id":     "bold blue",
+			"action":      "bold magenta",
+			"service":     "italic green",
+			"version":     "italic gray",
+			"error":       "bold red",
+			"database":    "blue",
+			"timeout":     "yellow",
+			"retry_count": "yellow",
+			"ip_address":  "cyan",
+			"user_agent":  "gray",
+			"email":       "blue",
+			"status":      "green",
+			"method":      "magenta",
+			"url":         "underline blue",
+			"duration":    "yellow",
+			"memory":      "cyan",
+			"cpu":         "green",
+			"disk":        "yellow",
+		},
+	},
+	// Darker 256-color shades that stay readable on a white background
+	config.ThemeLight: {
+		Timestamp:   "244",
+		Message:     "bold none",
+		Punctuation: "244",
+		Label:       "244",
+		Group:       "bold 90",
+		Trace:       "italic 244",
+		TraceFile:   "italic 24",
+		Levels:      config.LevelColors{Debug: "bold 31", Info: "bold 28", Warn: "bold 130", Error: "bold 160"},
+		Values: config.ValueColors{
+			String: "none", Number: "25", True: "bold 28", False: "bold 160",
+			Object: "90", Array: "25", Link: "underline 19", Address: "bold 24",
+			Good: "bold 28", Warning: "bold 130", Bad: "bold 160",
+			Path: "italic 244", Database: "19", Duration: "130",
+		},
+		JSON: config.JSONColors{
+			Key: "bold 24", String: "28", Number: "25", Bool: "bold 130",
+			Null: "160", Brace: "bold 90", Colon: "244", Comma: "244",
+		},
+		Fields: map[string]string{
+			"trace_id": "bold 24",
+			"span_id":  "bold 24",
+			"user_id":  "bold 19",
+			"action":   "bold 90",
+			"service":  "italic 28",
+			"version":  "italic 244",
+			"error":    "bold 160",
+		},
+	},
+	// Bold bright colors only, no gray or dim text
+	config.ThemeHighContrast: {
+		Timestamp:   "bright-white",
+		Message:     "bold bright-white",
+		Punctuation: "bright-white",
+		Label:       "bold bright-white",
+		Group:       "bold bright-magenta",
+		Trace:       "bright-white",
+		TraceFile:   "bold bright-cyan",
+		Levels:      config.LevelColors{Debug: "bold bright-cyan", Info: "bold bright-green", Warn: "bold bright-yellow", Error: "bold bright-red"},
+		Values: config.ValueColors{
+			String: "bright-white", Number: "bold bright-cyan", True: "bold bright-green", False: "bold bright-red",
+			Object: "bold bright-magenta", Array: "bold bright-cyan", Link: "bold underline bright-blue", Address: "bold bright-cyan",
+			Good: "bold bright-green", Warning: "bold bright-yellow", Bad: "bold bright-red",
+			Path: "bright-white", Database: "bold bright-blue", Duration: "bold bright-yellow",
+		},
+		JSON: config.JSONColors{
+			Key: "bold bright-cyan", String: "bold bright-green", Number: "bold bright-cyan", Bool: "bold bright-yellow",
+			Null: "bold bright-red", Brace: "bold bright-magenta", Colon: "bright-white", Comma: "bright-white",
+		},
+		Fields: map[string]string{
+			"error": "bold bright-red",
+		},
+	},
+}
+
+// newTheme resolves cfg on top of its base theme
+func newTheme(cfg config.ThemeConfig) *theme {
+	name := cfg.Name
+	base, ok := builtinThemes[name]
+	if !ok {
+		if name != "" {
+			diag.Warn("unknown console theme, using dark", "theme", name)
+		}
+		base = builtinThemes[config.ThemeDark]
+	}
+
+	pick := func(color, fallback string) string {
+		if color == "" {
+			color = fallback
+		}
+		return parseColor(color)
+	}
+	t := &theme{
+		colors:      true,
+		timestamp:   pick(cfg.Timestamp, base.Timestamp),
+		message:     pick(cfg.Message, base.Message),
+		punctuation: pick(cfg.Punctuation, base.Punctuation),
+		label:       pick(cfg.Label, base.Label),
+		group:       pick(cfg.Group, base.Group),
+		trace:       pick(cfg.Trace, base.Trace),
+		traceFile:   pick(cfg.TraceFile, base.TraceFile),
+
+		debug: pick(cfg.Levels.Debug, base.Levels.Debug),
+		info:  pick(cfg.Levels.Info, base.Levels.Info),
+		warn:  pick(cfg.Levels.Warn, base.Levels.Warn),
+		error: pick(cfg.Levels.Error, base.Levels.Error),
+
+		str:       pick(cfg.Values.String, base.Values.String),
+		number:    pick(cfg.Values.Number, base.Values.Number),
+		boolTrue:  pick(cfg.Values.True, base.Values.True),
+		boolFalse: pick(cfg.Values.False, base.Values.False),
+		object:    pick(cfg.Values.Object, base.Values.Object),
+		array:     pick(cfg.Values.Array, base.Values.Array),
+		link:      pick(cfg.Values.Link, base.Values.Link),
+		address:   pick(cfg.Values.Address, base.Values.Address),
+		good:      pick(cfg.Values.Good, base.Values.Good),
+		warning:   pick(cfg.Values.Warning, base.Values.Warning),
+		bad:       pick(cfg.Values.Bad, base.Values.Bad),
+		path:      pick(cfg.Values.Path, base.Values.Path),
+		database:  pick(cfg.Values.Database, base.Values.Database),
+		duration:  pick(cfg.Values.Duration, base.Values.Duration),
+
+		jsonKey:    pick(cfg.JSON.Key, base.JSON.Key),
+		jsonString: pick(cfg.JSON.String, base.JSON.String),
+		jsonNumber: pick(cfg.JSON.Number, base.JSON.Number),
+		jsonBool:   pick(cfg.JSON.Bool, base.JSON.Bool),
+		jsonNull:   pick(cfg.JSON.Null, base.JSON.Null),
+		jsonBrace:  pick(cfg.JSON.Brace, base.JSON.Brace),
+		jsonColon:  pick(cfg.JSON.Colon, base.JSON.Colon),
+		jsonComma:  pick(cfg.JSON.Comma, base.JSON.Comma),
+
+		fields: make(map[string]string, len(base.Fields)+len(cfg.Fields)),
+	}
+	for key, color := range base.Fields {
+		t.fields[key] = parseColor(color)
+	}
+	for key, color := range cfg.Fields {
+		t.fields[key] = parseColor(color)
+	}
+	return t
+}
+
+var colorNames = map[string]int{
+	"black": 30, "red": 31, "green": 32, "yellow": 33, "blue": 34, "magenta": 35, "cyan": 36, "white": 37,
+	"gray": 90, "grey": 90,
+	"bright-black": 90, "bright-red": 91, "bright-green": 92, "bright-yellow": 93,
+	"bright-blue": 94, "bright-magenta": 95, "bright-cyan": 96, "bright-white": 97,
+}
+
+var styleNames = map[string]int{"bold": 1, "dim": 2, "italic": 3, "underline": 4}
+
+// parseColor returns the escape sequence for a color of config.ThemeConfig.
+// Unknown words are reported and skipped.
+func parseColor(color string) string {
+	var params []string
+	for _, word := range strings.Fields(strings.ToLower(color)) {
+		if code, ok := styleNames[word]; ok {
+			params = append(params, strconv.Itoa(code))
+			continue
+		}
+		if code, ok := colorNames[word]; ok {
+			params = append(params, strconv.Itoa(code))
+			continue
+		}
+		if word == "none" {
+			continue
+		}
+		if n, err := strconv.Atoi(word); err == nil && n >= 0 && n <= 255 {
+			params = append(params, "38;5;"+word)
+			continue
+		}
+		var r, g, b uint8
+		if len(word) == 7 {
+			if _, err := fmt.Sscanf(word, "#%02x%02x%02x", &r, &g, &b); err == nil {
+				params = append(params, fmt.Sprintf("38;2;%d;%d;%d", r, g, b))
+				continue
+			}
+		}
+		diag.Warn("unknown console color, ignoring it", "color", word)
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "\033[" + strings.Join(params, ";") + "m"
+}
+
+// paint wraps s in color, or returns it as is without a color
+func paint(color, s string) string {
+	if color == "" {
+		return s
+	}
+	return color + s + Reset
+}
+
+// level returns the color of a record level
+func (t *theme) level(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return t.error
+	case level >= slog.LevelWarn:
+		return t.warn
+	case level >= slog.LevelInfo:
+		return t.info
+	default:
+		return t.debug
+	}
+}
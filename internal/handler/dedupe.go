@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+
+	"github.com/aaffriya/logger/config"
+	"github.com/aaffriya/logger/internal/diag"
+)
+
+// builtinKeys are written by the backends themselves or added by the handler
+// and can never be replaced by an attribute
+var builtinKeys = []string{"timestamp", "level", "message", "trace"}
+
+// duplicatePolicy returns the validated policy, keep_last by default
+func duplicatePolicy(policy string) string {
+	switch policy {
+	case config.DuplicateKeepLast, config.DuplicateKeepFirst, config.DuplicateRename:
+		return policy
+	case "":
+		return config.DuplicateKeepLast
+	}
+	diag.Warn("unknown duplicate key policy, keeping the last attribute", "policy", policy)
+	return config.DuplicateKeepLast
+}
+
+// earlierKey reports whether key is already used at the level attributes are
+// added to, and whether the attribute using it may be replaced
+type earlierKey func(key string) (taken, protected bool)
+
+// dedupeAttrs applies policy to attrs, which follow the attributes described
+// by earlier at the same level. Under keep_last, remove is called for each
+// replaceable earlier key that a new attribute replaces. Members of groups
+// are deduplicated among themselves. attrs is returned as is when it has no
+// duplicates.
+func dedupeAttrs(policy string, attrs []slog.Attr, earlier earlierKey, remove func(key string)) []slog.Attr {
+	if !hasDuplicates(attrs, earlier) {
+		return attrs
+	}
+
+	out := make([]slog.Attr, 0, len(attrs))
+	used := func(key string) bool {
+		if taken, _ := earlier(key); taken {
+			return true
+		}
+		return slices.ContainsFunc(out, func(a slog.Attr) bool { return a.Key == key })
+	}
+
+	for _, a := range attrs {
+		if a.Value.Kind() == slog.KindGroup {
+			members := dedupeAttrs(policy, a.Value.Group(), noEarlierKeys, nil)
+			a = slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}
+		}
+
+		taken, protected := earlier(a.Key)
+		i := slices.IndexFunc(out, func(b slog.Attr) bool { return b.Key == a.Key })
+		if !taken && i < 0 {
+			out = append(out, a)
+			continue
+		}
+
+		switch {
+		case policy == config.DuplicateKeepFirst:
+			continue
+		case policy == config.DuplicateRename || protected:
+			a.Key = renameKey(a.Key, used)
+		case taken:
+			remove(a.Key)
+		default:
+			out = slices.Delete(out, i, i+1)
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// hasDuplicates reports whether dedupeAttrs would change attrs
+func hasDuplicates(attrs []slog.Attr, earlier earlierKey) bool {
+	for i, a := range attrs {
+		if taken, _ := earlier(a.Key); taken {
+			return true
+		}
+		for _, b := range attrs[:i] {
+			if a.Key == b.Key {
+				return true
+			}
+		}
+		if a.Value.Kind() == slog.KindGroup && hasDuplicates(a.Value.Group(), noEarlierKeys) {
+			return true
+		}
+	}
+	return false
+}
+
+func noEarlierKeys(string) (bool, bool) {
+	return false, false
+}
+
+// renameKey returns key with the first of the suffixes _2, _3... that is not
+// used
+func renameKey(key string, used func(string) bool) string {
+	for n := 2; ; n++ {
+		if renamed := key + "_" + strconv.Itoa(n); !used(renamed) {
+			return renamed
+		}
+	}
+}
+
+// scopeKey reports whether key was added by WithAttrs at the level record
+// attributes go to, the innermost group of goas
+func scopeKey(goas []groupOrAttrs, key string) bool {
+	for i := len(goas) - 1; i >= 0 && goas[i].group == ""; i-- {
+		for _, a := range goas[i].attrs {
+			if a.Key == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// editScopeKey renames the attributes added by WithAttrs under key at the
+// innermost level, or removes them when renamed is empty. goas is copied, not
+// modified.
+func editScopeKey(goas []groupOrAttrs, key, renamed string) []groupOrAttrs {
+	goas = slices.Clone(goas)
+	for i := len(goas) - 1; i >= 0 && goas[i].group == ""; i-- {
+		attrs := make([]slog.Attr, 0, len(goas[i].attrs))
+		for _, a := range goas[i].attrs {
+			if a.Key == key {
+				if renamed == "" {
+					continue
+				}
+				a.Key = renamed
+			}
+			attrs = append(attrs, a)
+		}
+		goas[i].attrs = attrs
+	}
+	return goas
+}
+
+// protectedKey reports whether key is added by the logger at the top level,
+// either always or, for the keys in added, to this record
+func (h *Handler) protectedKey(key string, added [][]slog.Attr) bool {
+	if slices.Contains(builtinKeys, key) {
+		return true
+	}
+	if h.opts.AddSource && key == slog.SourceKey {
+		return true
+	}
+	for _, a := range h.defaults {
+		if a.Key == key {
+			return true
+		}
+	}
+	for _, attrs := range added {
+		for _, a := range attrs {
+			if a.Key == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dedupeRecord applies the duplicate key policy to the record attributes.
+// added holds the attributes the logger adds to this record, such as the
+// context fields. The returned goas differ from h.goas, and edited is set,
+// when attributes added by WithAttrs have to give way: to a later record
+// attribute under keep_last, or to a key the logger added.
+func (h *Handler) dedupeRecord(record []slog.Attr, added ...[]slog.Attr) (attrs []slog.Attr, goas []groupOrAttrs, edited bool) {
+	goas = h.goas
+	topLevel := len(h.groups) == 0
+
+	// Attributes added by WithAttrs were checked against the keys known when
+	// they were added, not against the ones of this record
+	if topLevel {
+		for _, attrs := range added {
+			for _, a := range attrs {
+				if !scopeKey(goas, a.Key) {
+					continue
+				}
+				renamed := ""
+				if h.duplicates != config.DuplicateKeepFirst {
+					renamed = renameKey(a.Key, func(key string) bool {
+						return scopeKey(goas, key) || h.protectedKey(key, added)
+					})
+				}
+				goas = editScopeKey(goas, a.Key, renamed)
+				edited = true
+			}
+		}
+	}
+
+	earlier := func(key string) (bool, bool) {
+		if topLevel && h.protectedKey(key, added) {
+			return true, true
+		}
+		return scopeKey(goas, key), false
+	}
+	remove := func(key string) {
+		goas = editScopeKey(goas, key, "")
+		edited = true
+	}
+	return dedupeAttrs(h.duplicates, record, earlier, remove), goas, edited
+}
+
+// dedupeWithAttrs applies the duplicate key policy to attributes added by
+// WithAttrs, returning them and the goas they follow
+func (h *Handler) dedupeWithAttrs(attrs []slog.Attr) ([]slog.Attr, []groupOrAttrs, bool) {
+	goas := h.goas
+	edited := false
+	earlier := func(key string) (bool, bool) {
+		if len(h.groups) == 0 && h.protectedKey(key, nil) {
+			return true, true
+		}
+		return scopeKey(goas, key), false
+	}
+	remove := func(key string) {
+		goas = editScopeKey(goas, key, "")
+		edited = true
+	}
+	return dedupeAttrs(h.duplicates, attrs, earlier, remove), goas, edited
+}
+
+// groupName returns name, renamed when it is already used at the level the
+// group is opened in. Groups are never dropped or replaced since that would
+// lose the attributes added to them later.
+func (h *Handler) groupName(name string) string {
+	used := func(key string) bool {
+		return (len(h.groups) == 0 && h.protectedKey(key, nil)) || scopeKey(h.goas, key)
+	}
+	if !used(name) {
+		return name
+	}
+	return renameKey(name, used)
+}
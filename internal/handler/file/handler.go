@@ -1,56 +1,128 @@
 package file
 
 import (
-	"encoding/json"
 	"io"
 	"log/slog"
 	"os"
 	"sync"
+	"time"
+
+	"github.com/aaffriya/logger/internal/encode"
 )
 
 type fileHandler struct {
-	file  *os.File
-	writer io.Writer
+	out    *Output
 	mu     *sync.Mutex
+	enc    *encode.Encoder
+	encode func([]byte, *encode.Encoder, slog.Record) []byte
+
+	// Records are collected in buf until it reaches bufSize or Flush is
+	// called. Buffering is off when bufSize is zero.
+	buf     []byte
+	bufSize int
+	bufTime time.Time
 }
 
 type FileHandler interface {
 	Handle(r slog.Record) error
+	Reopen() error
+	Flush() error
+}
+
+// NewFileHandler returns a JSON backend writing to w. With a positive
+// bufferSize records are buffered and written in batches.
+func NewFileHandler(w io.Writer, file *os.File, bufferSize int, enc *encode.Encoder) FileHandler {
+	return newFileHandler(w, file, bufferSize, enc, encodeJSON)
 }
 
-func NewFileHandler(w io.Writer, file *os.File) FileHandler {
+// NewLogfmtHandler returns a logfmt backend writing to w, buffered like
+// NewFileHandler
+func NewLogfmtHandler(w io.Writer, file *os.File, bufferSize int, enc *encode.Encoder) FileHandler {
+	return newFileHandler(w, file, bufferSize, enc, encodeLogfmt)
+}
+
+func newFileHandler(w io.Writer, file *os.File, bufferSize int, enc *encode.Encoder, encodeRecord func([]byte, *encode.Encoder, slog.Record) []byte) *fileHandler {
 	return &fileHandler{
-		file:   file,
-		writer: w,
-		mu:     &sync.Mutex{},
+		out:     NewOutput(w, file),
+		mu:      &sync.Mutex{},
+		enc:     enc,
+		encode:  encodeRecord,
+		bufSize: bufferSize,
 	}
 }
 
+// bufPool holds encode buffers. Buffers that grew past maxPooledBuf for an
+// unusually large record are dropped instead of being kept alive.
+var bufPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 1024)
+		return &buf
+	},
+}
+
+const maxPooledBuf = 64 << 10
+
 func (h *fileHandler) Handle(r slog.Record) error {
-	
-	logData := map[string]any{
-		"timestamp": r.Time.Format("2006-01-02T15:04:05.000Z07:00"),
-		"level":     r.Level.String(),
-		"message":   r.Message,
+	bufp := bufPool.Get().(*[]byte)
+	line := h.encode((*bufp)[:0], h.enc, r)
+	defer func() {
+		if cap(line) <= maxPooledBuf {
+			*bufp = line
+			bufPool.Put(bufp)
+		}
+	}()
+
+	// 🔐 Synchronize writes
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.bufSize <= 0 {
+		return h.out.WriteRecord(r.Time, line)
 	}
-	
-	r.Attrs(func(a slog.Attr) bool {
-		logData[a.Key] = a.Value.Any()
-		return true
-	})
-	
-	jsonBytes, err := json.Marshal(logData)
-	if err != nil {
-		return err
+
+	// Never let a buffered batch straddle a rotation
+	if len(h.buf) > 0 && h.out.NeedsRotation(r.Time, len(h.buf)+len(line)) {
+		if err := h.flushLocked(); err != nil {
+			return err
+		}
 	}
-	
-	jsonBytes = append(jsonBytes, '\n')
-	
-	// 🔐 Synchronize writes
+
+	h.buf = append(h.buf, line...)
+	if r.Time.After(h.bufTime) {
+		h.bufTime = r.Time
+	}
+	if len(h.buf) >= h.bufSize {
+		return h.flushLocked()
+	}
+	return nil
+}
+
+// Flush writes buffered records
+func (h *fileHandler) Flush() error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	
-	_, err = h.writer.Write(jsonBytes)
-	
+	return h.flushLocked()
+}
+
+func (h *fileHandler) flushLocked() error {
+	if len(h.buf) == 0 {
+		return nil
+	}
+	err := h.out.WriteRecord(h.bufTime, h.buf)
+	h.buf = h.buf[:0]
+	h.bufTime = time.Time{}
 	return err
 }
+
+// Reopen swaps the underlying file for a freshly opened one with the same
+// name while holding the write lock, so no record is written to the old inode
+// after it returns and none is lost in between.
+func (h *fileHandler) Reopen() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.flushLocked(); err != nil {
+		return err
+	}
+	return h.out.Reopen()
+}
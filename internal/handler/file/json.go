@@ -0,0 +1,150 @@
+package file
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/aaffriya/logger/internal/encode"
+)
+
+const timestampLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Prefix is the JSON encoding of attributes added with WithAttrs, made once
+// per derived handler instead of once per record. It travels in a record as
+// the value of an attribute with an empty key; attributes after it are
+// written inside the groups the prefix left open.
+type Prefix struct {
+	json    []byte
+	open    int      // groups opened in json
+	pending []string // groups opened after the last attributes, written only when a record has attributes
+}
+
+// NewPrefix returns a prefix holding attrs at the top level
+func NewPrefix(enc *encode.Encoder, attrs []slog.Attr) *Prefix {
+	return (&Prefix{}).WithAttrs(enc, attrs)
+}
+
+// WithAttrs returns a copy of p with attrs encoded inside its groups
+func (p *Prefix) WithAttrs(enc *encode.Encoder, attrs []slog.Attr) *Prefix {
+	if len(attrs) == 0 {
+		return p
+	}
+
+	q := &Prefix{json: slices.Clip(p.json), open: p.open}
+	comma := true
+	for _, group := range p.pending {
+		q.json = appendJSONGroupStart(q.json, group, comma)
+		q.open++
+		comma = false
+	}
+	for _, a := range attrs {
+		q.json = appendJSONAttr(q.json, enc, a, comma)
+		comma = true
+	}
+	return q
+}
+
+// WithGroup returns a copy of p whose later attributes go into group name
+func (p *Prefix) WithGroup(name string) *Prefix {
+	return &Prefix{json: p.json, open: p.open, pending: append(slices.Clip(p.pending), name)}
+}
+
+// encodeJSON appends r to buf as one line of JSON with the timestamp, level
+// and message first and the attributes in order
+func encodeJSON(buf []byte, enc *encode.Encoder, r slog.Record) []byte {
+	builtins := encode.BuiltinsOf(r)
+	buf = append(buf, '{')
+	// A zero time or empty message was removed or replaced by ReplaceAttr
+	switch {
+	case builtins.Time != nil:
+		buf = append(buf, `"timestamp":`...)
+		buf = enc.AppendJSON(buf, *builtins.Time)
+		buf = append(buf, ',')
+	case !r.Time.IsZero():
+		buf = append(buf, `"timestamp":"`...)
+		buf = r.Time.AppendFormat(buf, timestampLayout)
+		buf = append(buf, `",`...)
+	}
+	buf = append(buf, `"level":`...)
+	if builtins.Level != nil {
+		buf = enc.AppendJSON(buf, *builtins.Level)
+	} else {
+		buf = encode.AppendJSONString(buf, r.Level.String())
+	}
+	switch {
+	case builtins.Message != nil:
+		buf = append(buf, `,"message":`...)
+		buf = enc.AppendJSON(buf, *builtins.Message)
+	case r.Message != "":
+		buf = append(buf, `,"message":`...)
+		buf = encode.AppendJSONString(buf, r.Message)
+	}
+
+	var prefix *Prefix
+	open := 0
+	comma := true
+	var add func(a slog.Attr) bool
+	add = func(a slog.Attr) bool {
+		if p, ok := prefixOf(a); ok {
+			prefix = p
+			buf = append(buf, p.json...)
+			open = p.open
+			return true
+		}
+		// The handler passes the attributes of JSON sinks as one group with an
+		// empty key, which slog handlers inline
+		if a.Key == "" && a.Value.Kind() == slog.KindGroup {
+			for _, member := range a.Value.Group() {
+				add(member)
+			}
+			return true
+		}
+		// Other attributes with an empty key carry the Builtins written above
+		if a.Key == "" {
+			return true
+		}
+		if prefix != nil && open == prefix.open {
+			for _, group := range prefix.pending {
+				buf = appendJSONGroupStart(buf, group, comma)
+				open++
+				comma = false
+			}
+		}
+		buf = appendJSONAttr(buf, enc, a, comma)
+		comma = true
+		return true
+	}
+	r.Attrs(add)
+
+	for ; open > 0; open-- {
+		buf = append(buf, '}')
+	}
+	return append(buf, '}', '\n')
+}
+
+// prefixOf returns the prefix carried by a, if any
+func prefixOf(a slog.Attr) (*Prefix, bool) {
+	// Check the kind first, Any allocates for other kinds
+	if a.Key != "" || a.Value.Kind() != slog.KindAny {
+		return nil, false
+	}
+	p, ok := a.Value.Any().(*Prefix)
+	return p, ok
+}
+
+func appendJSONGroupStart(buf []byte, name string, comma bool) []byte {
+	if comma {
+		buf = append(buf, ',')
+	}
+	buf = encode.AppendJSONString(buf, name)
+	return append(buf, ':', '{')
+}
+
+func appendJSONAttr(buf []byte, enc *encode.Encoder, a slog.Attr, comma bool) []byte {
+	if comma {
+		buf = append(buf, ',')
+	}
+	buf = encode.AppendJSONString(buf, a.Key)
+	buf = append(buf, ':')
+	return enc.AppendJSON(buf, a.Value)
+}
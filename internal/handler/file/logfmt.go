@@ -0,0 +1,98 @@
+package file
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aaffriya/logger/internal/encode"
+)
+
+// encodeLogfmt encodes r as one line of key=value pairs. Group attributes are
+// flattened into dotted keys and values are quoted when they would not parse
+// back as a single token.
+func encodeLogfmt(buf []byte, enc *encode.Encoder, r slog.Record) []byte {
+	builtins := encode.BuiltinsOf(r)
+	switch {
+	case builtins.Time != nil:
+		buf = appendLogfmtPair(buf, "timestamp", enc.String(*builtins.Time))
+	case !r.Time.IsZero():
+		buf = appendLogfmtPair(buf, "timestamp", r.Time.Format("2006-01-02T15:04:05.000Z07:00"))
+	}
+	if builtins.Level != nil {
+		buf = appendLogfmtPair(buf, "level", enc.String(*builtins.Level))
+	} else {
+		buf = appendLogfmtPair(buf, "level", r.Level.String())
+	}
+	switch {
+	case builtins.Message != nil:
+		buf = appendLogfmtPair(buf, "message", enc.String(*builtins.Message))
+	case r.Message != "":
+		buf = appendLogfmtPair(buf, "message", r.Message)
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		buf = appendLogfmtAttr(buf, enc, "", a)
+		return true
+	})
+
+	return append(buf, '\n')
+}
+
+func appendLogfmtAttr(buf []byte, enc *encode.Encoder, prefix string, a slog.Attr) []byte {
+	v := a.Value.Resolve()
+	key := prefix + a.Key
+
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix = key + "."
+		}
+		for _, ga := range v.Group() {
+			buf = appendLogfmtAttr(buf, enc, prefix, ga)
+		}
+		return buf
+	}
+	if a.Key == "" {
+		return buf
+	}
+
+	return appendLogfmtPair(buf, key, enc.String(v))
+}
+
+func appendLogfmtPair(buf []byte, key, value string) []byte {
+	if len(buf) > 0 {
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, logfmtKey(key)...)
+	buf = append(buf, '=')
+	if needsLogfmtQuote(value) {
+		return strconv.AppendQuote(buf, value)
+	}
+	return append(buf, value...)
+}
+
+// logfmtKey replaces characters that would end the key early
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func needsLogfmtQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r == '=' || r == '"' || r == '\\' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
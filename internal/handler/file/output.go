@@ -0,0 +1,80 @@
+package file
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// Output is where a backend writes: a writer and, when it writes to a regular
+// file, that file, which Reopen replaces. It is not safe for concurrent use,
+// backends call it while holding their write lock.
+type Output struct {
+	file   *os.File
+	writer io.Writer
+}
+
+// reopener is implemented by writers that manage reopening their own file
+type reopener interface {
+	Reopen() error
+}
+
+// recordWriter is implemented by writers that rotate on record timestamps
+type recordWriter interface {
+	WriteRecord(t time.Time, p []byte) (int, error)
+	NeedsRotation(t time.Time, n int) bool
+}
+
+// NewOutput returns an Output writing to w. file is the file w writes to,
+// or nil when there is none.
+func NewOutput(w io.Writer, file *os.File) *Output {
+	return &Output{file: file, writer: w}
+}
+
+// Writer returns the writer written to
+func (o *Output) Writer() io.Writer {
+	return o.writer
+}
+
+// WriteRecord writes p, whole records of which the latest was logged at t.
+// Writers that rotate on record timestamps pick the file by t rather than
+// the current time.
+func (o *Output) WriteRecord(t time.Time, p []byte) error {
+	var err error
+	if rw, ok := o.writer.(recordWriter); ok {
+		_, err = rw.WriteRecord(t, p)
+	} else {
+		_, err = o.writer.Write(p)
+	}
+	return err
+}
+
+// NeedsRotation reports whether writing n bytes for a record logged at t
+// would go to a new file
+func (o *Output) NeedsRotation(t time.Time, n int) bool {
+	rw, ok := o.writer.(recordWriter)
+	return ok && rw.NeedsRotation(t, n)
+}
+
+// Reopen swaps the file written to for a freshly opened one with the same
+// name, or lets a writer managing its own file reopen it
+func (o *Output) Reopen() error {
+	if r, ok := o.writer.(reopener); ok {
+		return r.Reopen()
+	}
+	if o.file == nil {
+		return nil
+	}
+
+	file, err := os.OpenFile(o.file.Name(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+
+	old := o.file
+	o.file = file
+	if o.writer == io.Writer(old) {
+		o.writer = file
+	}
+	return old.Close()
+}
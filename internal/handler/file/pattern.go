@@ -0,0 +1,98 @@
+package file
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	IntervalHourly = "hourly"
+	IntervalDaily  = "daily"
+)
+
+// Default patterns used when only an interval is configured
+var intervalPatterns = map[string]string{
+	IntervalHourly: "%Y-%m-%d-%H",
+	IntervalDaily:  "%Y-%m-%d",
+}
+
+// Regular expressions matching the output of each supported directive
+var directivePatterns = map[byte]string{
+	'Y': `\d{4}`,
+	'y': `\d{2}`,
+	'm': `\d{2}`,
+	'd': `\d{2}`,
+	'H': `\d{2}`,
+	'M': `\d{2}`,
+	'S': `\d{2}`,
+	'j': `\d{3}`,
+	'%': `%`,
+}
+
+// formatPattern expands strftime-style directives in pattern
+func formatPattern(pattern string, t time.Time) string {
+	var builder strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 == len(pattern) {
+			builder.WriteByte(pattern[i])
+			continue
+		}
+
+		i++
+		switch pattern[i] {
+		case 'Y':
+			fmt.Fprintf(&builder, "%04d", t.Year())
+		case 'y':
+			fmt.Fprintf(&builder, "%02d", t.Year()%100)
+		case 'm':
+			fmt.Fprintf(&builder, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&builder, "%02d", t.Day())
+		case 'H':
+			fmt.Fprintf(&builder, "%02d", t.Hour())
+		case 'M':
+			fmt.Fprintf(&builder, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&builder, "%02d", t.Second())
+		case 'j':
+			fmt.Fprintf(&builder, "%03d", t.YearDay())
+		case '%':
+			builder.WriteByte('%')
+		default:
+			builder.WriteByte('%')
+			builder.WriteByte(pattern[i])
+		}
+	}
+	return builder.String()
+}
+
+// patternRegexp returns a regular expression source matching any name
+// formatPattern can produce for pattern
+func patternRegexp(pattern string) string {
+	var builder strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '%' && i+1 < len(pattern) {
+			if expr, ok := directivePatterns[pattern[i+1]]; ok {
+				builder.WriteString(expr)
+				i++
+				continue
+			}
+		}
+		builder.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+	}
+	return builder.String()
+}
+
+// truncateToInterval returns the start of the interval containing t
+func truncateToInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case IntervalDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	default:
+		return t
+	}
+}
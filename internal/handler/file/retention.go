@@ -0,0 +1,139 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aaffriya/logger/internal/diag"
+)
+
+type backupFile struct {
+	name    string
+	size    int64
+	modTime time.Time
+	stamp   time.Time
+	seq     int
+}
+
+// expire applies the retention policy periodically, so files age out even
+// when no rotation happens
+func (f *RotatingFile) expire() {
+	defer f.wg.Done()
+
+	interval := min(max(f.retention.MaxAge/2, time.Second), time.Hour)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		f.maintMu.Lock()
+		f.cleanup()
+		f.maintMu.Unlock()
+
+		select {
+		case <-ticker.C:
+		case <-f.stop:
+			return
+		}
+	}
+}
+
+// cleanup removes rotated files beyond the retention limits and reports each
+// removal. The caller must hold maintMu.
+func (f *RotatingFile) cleanup() {
+	r := f.retention
+	if r.MaxAge <= 0 && r.MaxCount <= 0 && r.MaxTotalSize <= 0 {
+		return
+	}
+
+	backups, err := f.backups()
+	if err != nil {
+		diag.Error("failed to list rotated log files", "path", f.path, "error", err)
+		return
+	}
+
+	var total int64
+	if info, err := os.Stat(f.active.Load().(string)); err == nil {
+		total = info.Size()
+	}
+
+	now := time.Now()
+	for i, b := range backups {
+		var reason string
+		switch {
+		case r.MaxAge > 0 && now.Sub(b.modTime) > r.MaxAge:
+			reason = "max_age"
+		case r.MaxCount > 0 && i >= r.MaxCount:
+			reason = "max_count"
+		case r.MaxTotalSize > 0 && total+b.size > r.MaxTotalSize:
+			reason = "max_total_size"
+		}
+
+		if reason == "" {
+			total += b.size
+			continue
+		}
+
+		if err := os.Remove(b.name); err != nil {
+			if !os.IsNotExist(err) {
+				diag.Error("failed to remove rotated log file", "file", b.name, "reason", reason, "error", err)
+			}
+			continue
+		}
+		diag.Info("removed rotated log file", "file", b.name, "reason", reason, "size", b.size, "modified", b.modTime)
+	}
+}
+
+// backups lists rotated files, newest first
+func (f *RotatingFile) backups() ([]backupFile, error) {
+	dir := filepath.Dir(f.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	active := filepath.Base(f.active.Load().(string))
+	var found []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || name == active {
+			continue
+		}
+		match := f.backupRe.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		b := backupFile{name: filepath.Join(dir, name), size: info.Size(), modTime: info.ModTime()}
+		if match[1] != "" {
+			b.stamp, _ = time.Parse(backupTimeFormat, match[1])
+		}
+		if match[2] != "" {
+			b.seq, _ = strconv.Atoi(match[2])
+		}
+		found = append(found, b)
+	}
+
+	// Modification times are coarse, so files rotated within the same tick
+	// are ordered by the timestamp and sequence in their names
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if !a.modTime.Equal(b.modTime) {
+			return a.modTime.After(b.modTime)
+		}
+		if !a.stamp.Equal(b.stamp) {
+			return a.stamp.After(b.stamp)
+		}
+		if a.seq != b.seq {
+			return a.seq > b.seq
+		}
+		return a.name > b.name
+	})
+	return found, nil
+}
@@ -0,0 +1,370 @@
+package file
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aaffriya/logger/config"
+	"github.com/aaffriya/logger/internal/diag"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// backupSuffix matches the suffix backupName appends to a rotated file
+const backupSuffix = `-(\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3})(?:\.(\d+))?`
+
+// RotatingFile is an io.Writer over a log file that is rotated once it grows
+// past the configured size or, when a pattern or interval is configured, once
+// a record falls into a new period. It is not safe for concurrent use: the
+// mutex of the fileHandler or console handler writing to it serializes
+// writes. Every write holds whole lines, one record or a buffered batch of
+// them, and the fileHandler flushes a batch before a record for which
+// NeedsRotation reports a new file, so a rotation always happens between
+// records.
+type RotatingFile struct {
+	path      string
+	cfg       config.RotationConfig
+	retention config.RetentionConfig
+	pattern   string
+	backupRe  *regexp.Regexp
+
+	file   *os.File
+	name   string
+	size   int64
+	opened time.Time
+
+	// active mirrors name for the background cleanup, which must not read
+	// fields owned by the writer
+	active atomic.Value
+
+	maintMu  sync.Mutex
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// OpenRotatingFile opens cfg.Path for appending. With a pattern or interval
+// the active file is named after the first record's timestamp and the path
+// becomes a symlink to it, so the file is only created on the first write.
+func OpenRotatingFile(cfg config.FileConfig) (*RotatingFile, error) {
+	path := cfg.Path
+	f := &RotatingFile{
+		path:      path,
+		cfg:       cfg.Rotation,
+		retention: cfg.Retention,
+		stop:      make(chan struct{}),
+	}
+	if f.retention.MaxCount == 0 {
+		f.retention.MaxCount = f.cfg.MaxBackups
+	}
+	f.active.Store("")
+
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	switch {
+	case f.cfg.Pattern != "":
+		if strings.ContainsRune(f.cfg.Pattern, filepath.Separator) {
+			return nil, fmt.Errorf("rotation pattern %q must be a file name", f.cfg.Pattern)
+		}
+		f.pattern = f.cfg.Pattern
+	case f.cfg.Interval != "":
+		layout, ok := intervalPatterns[f.cfg.Interval]
+		if !ok {
+			return nil, fmt.Errorf("unknown rotation interval %q", f.cfg.Interval)
+		}
+		f.pattern = stem + "-" + layout + ext
+	}
+
+	if f.pattern == "" {
+		f.backupRe = regexp.MustCompile("^" + regexp.QuoteMeta(stem) + backupSuffix + regexp.QuoteMeta(ext) + `(?:\.gz)?$`)
+		if err := f.open(path); err != nil {
+			return nil, err
+		}
+	} else {
+		patternExt := filepath.Ext(f.pattern)
+		if strings.Contains(patternExt, "%") {
+			patternExt = ""
+		}
+		patternStem := strings.TrimSuffix(f.pattern, patternExt)
+		f.backupRe = regexp.MustCompile("^" + patternRegexp(patternStem) + "(?:" + backupSuffix + ")?" + regexp.QuoteMeta(patternExt) + `(?:\.gz)?$`)
+	}
+
+	if f.retention.MaxAge > 0 {
+		f.wg.Add(1)
+		go f.expire()
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open(name string) error {
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.name = name
+	f.size = info.Size()
+	f.active.Store(name)
+	return nil
+}
+
+// Write writes p using the wall clock to pick the period
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	return f.WriteRecord(time.Now(), p)
+}
+
+// WriteRecord writes one encoded record whose timestamp is t. Time-based
+// rotation follows t rather than the wall clock, so replayed logs end up in
+// the same files every time. Records older than the one that opened the
+// active file never switch back to an earlier file.
+func (f *RotatingFile) WriteRecord(t time.Time, p []byte) (int, error) {
+	if f.pattern != "" {
+		if t.IsZero() {
+			t = time.Now()
+		}
+		if f.needsSwitch(t) {
+			if err := f.switchTo(t); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if f.exceedsSize(len(p)) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// NeedsRotation reports whether writing n bytes for a record at t would
+// start a new file
+func (f *RotatingFile) NeedsRotation(t time.Time, n int) bool {
+	if f.pattern != "" && !t.IsZero() && f.needsSwitch(t) {
+		return true
+	}
+	return f.exceedsSize(n)
+}
+
+func (f *RotatingFile) needsSwitch(t time.Time) bool {
+	return f.file == nil || (t.After(f.opened) && f.nameFor(t) != f.name)
+}
+
+func (f *RotatingFile) exceedsSize(n int) bool {
+	return f.cfg.MaxSize > 0 && f.size > 0 && f.size+int64(n) > f.cfg.MaxSize
+}
+
+// Close closes the active file and waits for pending compression and cleanup
+func (f *RotatingFile) Close() error {
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+	}
+	f.stopOnce.Do(func() { close(f.stop) })
+	f.wg.Wait()
+	return err
+}
+
+// Reopen reopens the active file by name, so writes follow a file that was
+// renamed or removed by an external tool such as logrotate. Like Write it
+// must be serialized by the fileHandler mutex.
+func (f *RotatingFile) Reopen() error {
+	if f.file == nil {
+		return nil
+	}
+
+	old := f.file
+	if err := f.open(f.name); err != nil {
+		return err
+	}
+	if f.pattern != "" {
+		f.updateLink()
+	}
+	return old.Close()
+}
+
+func (f *RotatingFile) nameFor(t time.Time) string {
+	t = truncateToInterval(t, f.cfg.Interval)
+	return filepath.Join(filepath.Dir(f.path), formatPattern(f.pattern, t))
+}
+
+// switchTo moves to the file of the period containing t
+func (f *RotatingFile) switchTo(t time.Time) error {
+	previous := ""
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		previous = f.name
+	}
+
+	if err := f.open(f.nameFor(t)); err != nil {
+		return err
+	}
+	f.opened = t
+	f.updateLink()
+
+	if previous != "" && previous != f.name {
+		f.wg.Add(1)
+		go f.maintain(previous)
+	}
+	return nil
+}
+
+// updateLink atomically points the configured path at the active file so
+// tail -F keeps following the log across rotations. Only a symlink is ever
+// replaced: a regular file at the path, such as a log written by an earlier
+// run without a pattern, is moved aside first.
+func (f *RotatingFile) updateLink() {
+	if f.name == f.path {
+		return
+	}
+
+	if info, err := os.Lstat(f.path); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		if !info.Mode().IsRegular() {
+			diag.Error("cannot link active log file, the path is not a file or symlink", "link", f.path)
+			return
+		}
+		moved := backupName(f.path, time.Now())
+		if err := os.Rename(f.path, moved); err != nil {
+			diag.Error("failed to move a log file out of the way of the active file link", "file", f.path, "error", err)
+			return
+		}
+		diag.Warn("moved a log file out of the way of the active file link", "file", f.path, "moved_to", moved)
+	}
+
+	target, err := filepath.Rel(filepath.Dir(f.path), f.name)
+	if err != nil {
+		target = f.name
+	}
+
+	tmp := f.path + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		diag.Error("failed to link active log file", "link", f.path, "error", err)
+		return
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		os.Remove(tmp)
+		diag.Error("failed to link active log file", "link", f.path, "error", err)
+	}
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	name := f.name
+	backup := backupName(name, time.Now())
+	if err := os.Rename(name, backup); err != nil {
+		// Keep logging into the old file rather than losing records
+		if openErr := f.open(name); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+
+	if err := f.open(name); err != nil {
+		return err
+	}
+
+	f.wg.Add(1)
+	go f.maintain(backup)
+	return nil
+}
+
+// backupName returns a free name such as app-2006-01-02T15-04-05.000.log
+func backupName(name string, t time.Time) string {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext) + "-" + t.Format(backupTimeFormat)
+	backup := stem + ext
+	for i := 1; fileExists(backup) || fileExists(backup+".gz"); i++ {
+		backup = fmt.Sprintf("%s.%d%s", stem, i, ext)
+	}
+	return backup
+}
+
+// maintain compresses a file that is no longer written to and applies the
+// retention policy. It runs in the background so the writer never waits on
+// gzip or directory scans.
+func (f *RotatingFile) maintain(backup string) {
+	defer f.wg.Done()
+
+	f.maintMu.Lock()
+	defer f.maintMu.Unlock()
+
+	if f.cfg.Compress {
+		// An earlier cleanup may already have removed the file
+		if err := compressFile(backup); err != nil && !os.IsNotExist(err) {
+			diag.Error("failed to compress rotated log file", "file", backup, "error", err)
+		}
+	}
+
+	f.cleanup()
+}
+
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := name + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// Keep the original modification time so backups stay ordered by age
+	if info, err := src.Stat(); err == nil {
+		os.Chtimes(tmp, info.ModTime(), info.ModTime())
+	}
+
+	if err := os.Rename(tmp, name+".gz"); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(name)
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
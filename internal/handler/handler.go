@@ -2,13 +2,13 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log/slog"
-	"os"
-	"strings"
+	"slices"
+	"sync"
 
 	"github.com/aaffriya/logger/config"
-	consolehandler "github.com/aaffriya/logger/internal/handler/console"
 	filehandler "github.com/aaffriya/logger/internal/handler/file"
 	"github.com/aaffriya/logger/internal/utils"
 	ctxmeta "github.com/aaffriya/logger/pkg/context"
@@ -19,168 +19,342 @@ type LogHandler interface {
 }
 
 type Handler struct {
-	config  *config.LoggerConfig
-	writer  io.Writer
-	opts    *slog.HandlerOptions
-	handler LogHandler
-	attrs   []slog.Attr
-	groups  []string
-	isFile  bool
+	config     *config.LoggerConfig
+	opts       *slog.HandlerOptions
+	sinks      []*sink
+	defaults   []slog.Attr           // service and version after ReplaceAttr
+	duplicates string                // duplicate key policy
+	prefixes   []*filehandler.Prefix // per sink, the encoded defaults and WithAttrs of JSON sinks
+	goas       []groupOrAttrs        // WithGroup and WithAttrs calls in order
+	groups     []string              // open groups, the group path of new attributes
+	queued     bool                  // a sink keeps records after handling them, so attributes are never pooled
 }
 
+// groupOrAttrs is either a group opened by WithGroup or attributes added by
+// WithAttrs
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewHandler returns a handler with a single sink writing to w in
+// config.Format. The default auto format writes JSON to regular files and
+// pretty console output to anything else. opts.Level, when set, takes
+// precedence over config.Level.
 func NewHandler(config *config.LoggerConfig, opts *slog.HandlerOptions, w io.Writer) slog.Handler {
-	if opts == nil {
-		opts = &slog.HandlerOptions{}
-	}
+	opts = handlerOptions(opts, config.Level)
 
-	var logLevel slog.Level
-	switch config.Level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
+	backend, json := newBackend(config, config.Format, &config.Pretty, w)
+	h := &Handler{
+		config: config,
+		opts:   opts,
+		sinks: []*sink{{
+			level:   opts.Level,
+			stack:   config.Stack,
+			handler: backend,
+			json:    json,
+		}},
+		groups: make([]string, 0),
 	}
-	opts.Level = &logLevel
+	_, h.queued = backend.(*asyncHandler)
+	h.initDefaults()
+	return h
+}
 
-	// Determine if this is a file writer by checking if it's an *os.File
-	isFile := false
-	if f, ok := w.(*os.File); ok {
-		fd := f.Fd()
-		if fd != os.Stdout.Fd() && fd != os.Stderr.Fd() {
-			isFile = true // Regular file, not stdout/stderr
-		}
-	}
+// NewSinkHandler returns a handler that fans every record out to sinks. The
+// record is enriched once and each sink applies its own level, format and
+// stack settings. Sinks without a level use opts.Level or else config.Level.
+func NewSinkHandler(config *config.LoggerConfig, opts *slog.HandlerOptions, sinks ...Sink) slog.Handler {
+	opts = handlerOptions(opts, config.Level)
 
-	h := Handler{
+	h := &Handler{
 		config: config,
 		opts:   opts,
-		writer: w,
-		attrs:  make([]slog.Attr, 0),
+		sinks:  make([]*sink, 0, len(sinks)),
 		groups: make([]string, 0),
-		isFile: isFile,
 	}
-	if h.isFile {
-		if file, ok := h.writer.(*os.File); ok {
-			h.handler = filehandler.NewFileHandler(h.writer, file)
-		} else {
-			h.handler = filehandler.NewFileHandler(h.writer, nil)
+	for _, s := range sinks {
+		sink := newSink(config, s.Config, opts.Level, s.Writer)
+		if _, ok := sink.handler.(*asyncHandler); ok {
+			h.queued = true
+		}
+		h.sinks = append(h.sinks, sink)
+	}
+	h.initDefaults()
+	return h
+}
+
+// initDefaults passes the default fields through ReplaceAttr once and
+// pre-encodes them for the JSON sinks
+func (h *Handler) initDefaults() {
+	h.duplicates = duplicatePolicy(h.config.DuplicateKeys)
+	h.defaults = replaceAttrs(h.opts.ReplaceAttr, nil, []slog.Attr{
+		slog.String("service", h.config.DefaultFields.Service),
+		slog.String("version", h.config.DefaultFields.Version),
+	})
+	h.buildPrefixes()
+}
+
+// buildPrefixes encodes the defaults and the WithGroup and WithAttrs calls
+// for the JSON sinks
+func (h *Handler) buildPrefixes() {
+	h.prefixes = make([]*filehandler.Prefix, len(h.sinks))
+	for i, s := range h.sinks {
+		if s.json == nil {
+			continue
+		}
+		p := filehandler.NewPrefix(s.json, h.defaults)
+		for _, goa := range h.goas {
+			if goa.group != "" {
+				p = p.WithGroup(goa.group)
+			} else {
+				p = p.WithAttrs(s.json, goa.attrs)
+			}
 		}
-	} else {
-		h.handler = consolehandler.NewPrettyHandler(h.writer, &h.config.Pretty)
+		h.prefixes[i] = p
 	}
-	return &h
+}
+
+// handlerOptions returns a copy of opts, so the caller's options are never
+// modified, with the level defaulting to level
+func handlerOptions(opts *slog.HandlerOptions, level string) *slog.HandlerOptions {
+	copied := slog.HandlerOptions{}
+	if opts != nil {
+		copied = *opts
+	}
+	if copied.Level == nil {
+		copied.Level = parseLevel(level)
+	}
+	return &copied
 }
 
 func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
-	minLevel := slog.LevelInfo
-	if h.opts.Level != nil {
-		minLevel = h.opts.Level.Level()
+	for _, s := range h.sinks {
+		if level >= s.level.Level() {
+			return true
+		}
 	}
-	return level >= minLevel
+	return false
 }
 
 func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
-	var recordAttrs []slog.Attr
+	var sinksBuf [4]*sink
+	sinks := sinksBuf[:0]
+	for _, s := range h.sinks {
+		if r.Level >= s.level.Level() {
+			sinks = append(sinks, s)
+		}
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	scratch := h.getScratch(r.NumAttrs())
+	defer h.putScratch(scratch)
 	r.Attrs(func(a slog.Attr) bool {
-		recordAttrs = append(recordAttrs, a)
+		scratch.record = append(scratch.record, a)
 		return true
 	})
 
-	allAttrs := h.prepareLogAttrs(ctx, r.Level, recordAttrs)
+	replaced, builtins, replacedFields := replaceBuiltins(h.opts.ReplaceAttr, r)
+	shared := h.prepareLogAttrs(ctx, r, builtins, scratch.record, sinks)
+
+	var errs []error
+	for i, s := range h.sinks {
+		if r.Level < s.level.Level() {
+			continue
+		}
+		// The prefix is stale when deduplication edited the WithAttrs attributes
+		prefix := h.prefixes[i]
+		if shared.edited {
+			prefix = nil
+		}
+		newRecord := slog.NewRecord(replaced.Time, replaced.Level, replaced.Message, r.PC)
+		if replacedFields != nil {
+			newRecord.AddAttrs(slog.Any("", replacedFields))
+		}
+		attrs := scratch.sink[:0]
+		if h.queued {
+			// Queued records are encoded later, so each needs attributes of its own
+			attrs = nil
+		}
+		scratch.sink = shared.appendForSink(attrs, s, prefix, r.Level, h.opts.ReplaceAttr)
+		if prefix != nil {
+			// A single group with an empty key, which the JSON encoder inlines,
+			// keeps the attributes within the inline storage of the record
+			newRecord.AddAttrs(slog.Attr{Value: slog.GroupValue(scratch.sink...)})
+		} else {
+			newRecord.AddAttrs(scratch.sink...)
+		}
+
+		if err := s.handler.Handle(newRecord); err != nil {
+			errs = append(errs, s.wrapErr(err))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// scratch holds the attribute slices of one Handle call
+type scratch struct {
+	record []slog.Attr // attributes of the record
+	sink   []slog.Attr // attributes of the record built for a sink
+}
+
+var scratchPool = sync.Pool{
+	New: func() any {
+		return &scratch{record: make([]slog.Attr, 0, 16), sink: make([]slog.Attr, 0, 16)}
+	},
+}
+
+// getScratch returns slices for a record with n attributes. They come from
+// scratchPool unless a sink queues records, which keep referring to them.
+func (h *Handler) getScratch(n int) *scratch {
+	if h.queued {
+		return &scratch{record: make([]slog.Attr, 0, n)}
+	}
+	return scratchPool.Get().(*scratch)
+}
 
-	newRecord := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
-	newRecord.AddAttrs(allAttrs...)
+func (h *Handler) putScratch(s *scratch) {
+	if h.queued || cap(s.record) > maxPooledAttrs || cap(s.sink) > maxPooledAttrs {
+		return
+	}
+	s.record = s.record[:0]
+	s.sink = s.sink[:0]
+	scratchPool.Put(s)
+}
 
-	return h.handler.Handle(newRecord)
+const maxPooledAttrs = 256
+
+// sharedAttrs is the enrichment of one record, computed once and shared by
+// every sink the record goes to
+type sharedAttrs struct {
+	builtins  []slog.Attr // time, level or message turned into attributes by ReplaceAttr
+	context   []slog.Attr
+	trace     []string // deep enough for every sink, starting at traceSkip
+	traceSkip int
+	source    []slog.Attr
+	record    []slog.Attr // record attributes, not yet nested in the handler's groups
+	fields    []slog.Attr // defaults, WithAttrs and record attributes nested in groups
+	edited    bool        // fields hold WithAttrs attributes changed by deduplication
 }
 
-func (h *Handler) prepareLogAttrs(ctx context.Context, level slog.Level, recordAttrs []slog.Attr) []slog.Attr {
-	attrs := make([]slog.Attr, 0, 10+len(recordAttrs)+len(h.attrs))
+func (h *Handler) prepareLogAttrs(ctx context.Context, r slog.Record, builtins, recordAttrs []slog.Attr, sinks []*sink) sharedAttrs {
+	shared := sharedAttrs{builtins: builtins}
+	level := r.Level
+	replace := h.opts.ReplaceAttr
 
 	if ctx != nil {
 		contextData := ctxmeta.FromContext(ctx)
 		if contextData.TraceID != "" {
-			attrs = append(attrs, slog.String("trace_id", contextData.TraceID))
+			shared.context = append(shared.context, slog.String("trace_id", contextData.TraceID))
 		}
 		if contextData.SpanID != "" {
-			attrs = append(attrs, slog.String("span_id", contextData.SpanID))
+			shared.context = append(shared.context, slog.String("span_id", contextData.SpanID))
 		}
 		if contextData.TraceFlags != "" {
-			attrs = append(attrs, slog.String("trace_flags", contextData.TraceFlags))
+			shared.context = append(shared.context, slog.String("trace_flags", contextData.TraceFlags))
 		}
 		if contextData.UserID != "" {
-			attrs = append(attrs, slog.String("user_id", contextData.UserID))
+			shared.context = append(shared.context, slog.String("user_id", contextData.UserID))
 		}
 		if contextData.Action != "" {
-			attrs = append(attrs, slog.String("action", contextData.Action))
+			shared.context = append(shared.context, slog.String("action", contextData.Action))
 		}
+		shared.context = replaceAttrs(replace, nil, shared.context)
 	}
 
-	if h.config.Stack.Enabled {
-		var stackDepth int
-		switch level {
-		case slog.LevelError:
-			stackDepth = h.config.Stack.Depth.Error
-		case slog.LevelWarn:
-			stackDepth = h.config.Stack.Depth.Warn
-		case slog.LevelInfo:
-			stackDepth = h.config.Stack.Depth.Info
-		case slog.LevelDebug:
-			stackDepth = h.config.Stack.Depth.Debug
+	// Capture one trace covering the frames of every sink, appendForSink slices it
+	from, to := -1, 0
+	for _, s := range sinks {
+		if depth := s.stackDepth(level); depth > 0 {
+			if from < 0 || s.stack.Skip < from {
+				from = s.stack.Skip
+			}
+			to = max(to, s.stack.Skip+depth)
 		}
+	}
+	if from >= 0 {
+		shared.trace = utils.GetStackTrace(from, to-from)
+		shared.traceSkip = from
+	}
 
-		if stackDepth > 0 {
-			attrs = append(attrs, slog.Any("trace", utils.GetStackTrace(h.config.Stack.Skip, stackDepth)))
+	if h.opts.AddSource {
+		if src := r.Source(); src != nil {
+			shared.source = replaceAttrs(replace, nil, []slog.Attr{slog.Any(slog.SourceKey, src)})
 		}
 	}
 
-	attrs = append(attrs,
-		slog.String("service", h.config.DefaultFields.Service),
-		slog.String("version", h.config.DefaultFields.Version),
-	)
-
-	attrs = append(attrs, h.attrs...)
-
-	if len(h.groups) > 0 {
-		attrs = append(attrs, h.applyGroups(recordAttrs)...)
-	} else {
-		attrs = append(attrs, recordAttrs...)
+	record, goas, edited := h.dedupeRecord(replaceAttrs(replace, h.groups, recordAttrs), shared.builtins, shared.context, shared.source)
+	shared.record = record
+	shared.edited = edited
+	// Only sinks without a usable pre-encoded prefix need the nested attributes
+	for _, s := range sinks {
+		if s.json == nil || edited {
+			shared.fields = append(h.defaults[:len(h.defaults):len(h.defaults)], applyGroups(goas, record)...)
+			break
+		}
 	}
 
-	return attrs
+	return shared
 }
 
-func (h *Handler) applyGroups(attrs []slog.Attr) []slog.Attr {
-	if len(h.groups) == 0 {
-		return attrs
+// appendForSink appends the attributes of the record as seen by s to attrs.
+// JSON sinks get their pre-encoded prefix as an attribute with an empty key,
+// followed by the record attributes it leaves to be nested.
+func (a *sharedAttrs) appendForSink(attrs []slog.Attr, s *sink, prefix *filehandler.Prefix, level slog.Level, replace func([]string, slog.Attr) slog.Attr) []slog.Attr {
+	attrs = append(attrs, a.builtins...)
+	attrs = append(attrs, a.context...)
+
+	if depth := s.stackDepth(level); depth > 0 {
+		start := min(s.stack.Skip-a.traceSkip, len(a.trace))
+		end := min(start+depth, len(a.trace))
+		attrs = append(attrs, replaceAttrs(replace, nil, []slog.Attr{slog.Any("trace", a.trace[start:end])})...)
 	}
 
-	groupPrefix := strings.Join(h.groups, ".")
-	groupedAttrs := make([]slog.Attr, len(attrs))
+	attrs = append(attrs, a.source...)
+	if prefix != nil {
+		attrs = append(attrs, slog.Any("", prefix))
+		return append(attrs, a.record...)
+	}
+	return append(attrs, a.fields...)
+}
 
-	for i, attr := range attrs {
-		groupedAttrs[i] = slog.Attr{
-			Key:   groupPrefix + "." + attr.Key,
-			Value: attr.Value,
+// applyGroups nests the record attrs in the groups of goas, together with
+// the attributes added by WithAttrs. Groups left without attributes are
+// dropped.
+func applyGroups(goas []groupOrAttrs, attrs []slog.Attr) []slog.Attr {
+	for i := len(goas) - 1; i >= 0; i-- {
+		goa := goas[i]
+		if goa.group == "" {
+			attrs = append(goa.attrs[:len(goa.attrs):len(goa.attrs)], attrs...)
+			continue
+		}
+		if len(attrs) > 0 {
+			attrs = []slog.Attr{{Key: goa.group, Value: slog.GroupValue(attrs...)}}
 		}
 	}
-
-	return groupedAttrs
+	return attrs
 }
 
 func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	newAttrs := make([]slog.Attr, len(h.attrs)+len(attrs))
-	copy(newAttrs, h.attrs)
-	copy(newAttrs[len(h.attrs):], attrs)
+	attrs = slices.Clone(replaceAttrs(h.opts.ReplaceAttr, h.groups, attrs))
+	attrs, goas, edited := h.dedupeWithAttrs(attrs)
+	if len(attrs) == 0 && !edited {
+		return h
+	}
 
 	handler := h.clone()
-	handler.attrs = newAttrs
+	handler.goas = append(goas[:len(goas):len(goas)], groupOrAttrs{attrs: attrs})
+	if edited {
+		handler.buildPrefixes()
+		return handler
+	}
+	for i, s := range h.sinks {
+		if s.json != nil {
+			handler.prefixes[i] = h.prefixes[i].WithAttrs(s.json, attrs)
+		}
+	}
 	return handler
 }
 
@@ -188,25 +362,94 @@ func (h *Handler) WithGroup(name string) slog.Handler {
 	if name == "" {
 		return h
 	}
+	name = h.groupName(name)
 
 	newGroups := make([]string, len(h.groups)+1)
 	copy(newGroups, h.groups)
 	newGroups[len(h.groups)] = name
 
 	handler := h.clone()
+	handler.goas = append(handler.goas, groupOrAttrs{group: name})
 	handler.groups = newGroups
+	for i, p := range h.prefixes {
+		if p != nil {
+			handler.prefixes[i] = p.WithGroup(name)
+		}
+	}
 
 	return handler
 }
 
+// Flush waits for queued records to be written and flushes buffered output
+// of every sink
+func (h *Handler) Flush(ctx context.Context) error {
+	var errs []error
+	for _, s := range h.sinks {
+		var err error
+		switch f := s.handler.(type) {
+		case interface{ Flush(context.Context) error }:
+			err = f.Flush(ctx)
+		case interface{ Flush() error }:
+			err = f.Flush()
+		}
+		if err != nil {
+			errs = append(errs, s.wrapErr(err))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// Close drains queued records and stops background writing. Records logged
+// afterwards are written synchronously.
+func (h *Handler) Close(ctx context.Context) error {
+	var errs []error
+	for _, s := range h.sinks {
+		var err error
+		switch c := s.handler.(type) {
+		case interface{ Close(context.Context) error }:
+			err = c.Close(ctx)
+		case interface{ Flush() error }:
+			err = c.Flush()
+		}
+		if err != nil {
+			errs = append(errs, s.wrapErr(err))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// Reopen reopens the log files of the backends writing to files, in any
+// format, see logger.Reopen
+func (h *Handler) Reopen() error {
+	var errs []error
+	for _, s := range h.sinks {
+		if r, ok := s.handler.(interface{ Reopen() error }); ok {
+			if err := r.Reopen(); err != nil {
+				errs = append(errs, s.wrapErr(err))
+			}
+		}
+	}
+	return joinErrors(errs)
+}
+
+// joinErrors keeps a single error as is, so callers can compare it directly
+func joinErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
 func (h *Handler) clone() *Handler {
 	return &Handler{
-		writer:  h.writer,
-		opts:    h.opts,
-		config:  h.config,
-		handler: h.handler,
-		attrs:   append([]slog.Attr(nil), h.attrs...),
-		groups:  append([]string(nil), h.groups...),
-		isFile:  h.isFile,
+		opts:       h.opts,
+		config:     h.config,
+		sinks:      h.sinks,
+		defaults:   h.defaults,
+		duplicates: h.duplicates,
+		prefixes:   append([]*filehandler.Prefix(nil), h.prefixes...),
+		goas:       h.goas[:len(h.goas):len(h.goas)],
+		groups:     append([]string(nil), h.groups...),
+		queued:     h.queued,
 	}
 }
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/aaffriya/logger/internal/encode"
+)
+
+// replaceAttrs resolves attrs and passes them through replace, if set,
+// recursing into groups with their path like slog's own handlers do.
+// Attributes with an empty key and empty groups are dropped, and the members
+// of a group with an empty key are inlined.
+func replaceAttrs(replace func([]string, slog.Attr) slog.Attr, groups []string, attrs []slog.Attr) []slog.Attr {
+	if replace == nil && !needsReplace(attrs) {
+		return attrs
+	}
+
+	replaced := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() != slog.KindGroup {
+			if replace != nil {
+				a = replace(groups, a)
+			}
+			if a.Key != "" {
+				replaced = append(replaced, a)
+			}
+			continue
+		}
+
+		if a.Key == "" {
+			replaced = append(replaced, replaceAttrs(replace, groups, a.Value.Group())...)
+			continue
+		}
+		path := append(groups[:len(groups):len(groups)], a.Key)
+		if members := replaceAttrs(replace, path, a.Value.Group()); len(members) > 0 {
+			replaced = append(replaced, slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)})
+		}
+	}
+	return replaced
+}
+
+// needsReplace reports whether replaceAttrs would change attrs without a
+// replace function
+func needsReplace(attrs []slog.Attr) bool {
+	for _, a := range attrs {
+		if a.Key == "" || a.Value.Kind() == slog.KindGroup || a.Value.Kind() == slog.KindLogValuer {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceBuiltins passes the time, level and message of r through replace
+// under the slog.TimeKey, slog.LevelKey and slog.MessageKey keys. A value of
+// the same kind under the same key replaces the built-in field, and a value
+// of another kind under the same key is returned in the Builtins for the
+// backends to write in its place, like slog.JSONHandler does. Otherwise the
+// time and message are removed from the record and the result, unless it was
+// dropped, is returned to be logged as a regular attribute. The level is
+// always kept since backends need it.
+func replaceBuiltins(replace func([]string, slog.Attr) slog.Attr, r slog.Record) (slog.Record, []slog.Attr, *encode.Builtins) {
+	if replace == nil {
+		return r, nil, nil
+	}
+
+	var extra []slog.Attr
+	var builtins encode.Builtins
+	t := r.Time
+	if !t.IsZero() {
+		a := replace(nil, slog.Time(slog.TimeKey, t))
+		if a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+			t = a.Value.Time()
+		} else {
+			t = time.Time{}
+			if a.Key == slog.TimeKey {
+				value := a.Value
+				builtins.Time = &value
+			} else if a.Key != "" {
+				extra = append(extra, a)
+			}
+		}
+	}
+
+	level := r.Level
+	a := replace(nil, slog.Any(slog.LevelKey, level))
+	if lv, ok := a.Value.Any().(slog.Level); ok && a.Key == slog.LevelKey {
+		level = lv
+	} else if a.Key == slog.LevelKey {
+		value := a.Value
+		builtins.Level = &value
+	} else if a.Key != "" {
+		extra = append(extra, a)
+	}
+
+	message := r.Message
+	a = replace(nil, slog.String(slog.MessageKey, message))
+	if a.Key == slog.MessageKey && a.Value.Kind() == slog.KindString {
+		message = a.Value.String()
+	} else {
+		message = ""
+		if a.Key == slog.MessageKey {
+			value := a.Value
+			builtins.Message = &value
+		} else if a.Key != "" {
+			extra = append(extra, a)
+		}
+	}
+
+	replaced := slog.NewRecord(t, level, message, r.PC)
+	if builtins == (encode.Builtins{}) {
+		return replaced, extra, nil
+	}
+	return replaced, extra, &builtins
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/aaffriya/logger/config"
+	"github.com/aaffriya/logger/internal/diag"
+	"github.com/aaffriya/logger/internal/encode"
+	consolehandler "github.com/aaffriya/logger/internal/handler/console"
+	filehandler "github.com/aaffriya/logger/internal/handler/file"
+)
+
+// Sink pairs the configuration of one output with the writer it logs to
+type Sink struct {
+	Config config.SinkConfig
+	Writer io.Writer
+}
+
+type sink struct {
+	name    string
+	level   slog.Leveler
+	stack   config.StackConfig
+	handler LogHandler
+	json    *encode.Encoder // set for JSON sinks, which take WithAttrs pre-encoded
+}
+
+func newSink(cfg *config.LoggerConfig, sc config.SinkConfig, defaultLevel slog.Leveler, w io.Writer) *sink {
+	level := defaultLevel
+	if sc.Level != "" {
+		level = parseLevel(sc.Level)
+	}
+	format := sc.Format
+	if format == "" {
+		format = cfg.Format
+	}
+	stack := cfg.Stack
+	if sc.Stack != nil {
+		stack = *sc.Stack
+	}
+	pretty := &cfg.Pretty
+	if sc.Pretty != nil {
+		pretty = sc.Pretty
+	}
+
+	handler, json := newBackend(cfg, format, pretty, w)
+	return &sink{
+		name:    sc.Name,
+		level:   level,
+		stack:   stack,
+		handler: handler,
+		json:    json,
+	}
+}
+
+// newBackend builds the formatting backend for w, wrapped in an async queue
+// when cfg.Async is enabled. See config.FormatAuto for the default format.
+// The encoder is returned for JSON backends only.
+func newBackend(cfg *config.LoggerConfig, format string, pretty *config.PrettyConfig, w io.Writer) (LogHandler, *encode.Encoder) {
+	switch format {
+	case config.FormatPretty, config.FormatPrettyText, config.FormatPrettyJSON, config.FormatJSON, config.FormatLogfmt:
+	case "", config.FormatAuto:
+		format = autoFormat(w)
+	default:
+		diag.Warn("unknown log format, detecting it from the writer", "format", format)
+		format = autoFormat(w)
+	}
+
+	bufferSize := 0
+	if cfg.Async.Enabled {
+		bufferSize = cfg.Async.BufferSize
+		if bufferSize <= 0 {
+			bufferSize = defaultBufferSize
+		}
+	}
+
+	// Only regular files are reopened, never stdout or stderr
+	var file *os.File
+	if f, ok := w.(*os.File); ok && isFileWriter(w) {
+		file = f
+	}
+
+	enc := encode.New(cfg.Encoding)
+
+	var backend LogHandler
+	var json *encode.Encoder
+	switch format {
+	case config.FormatJSON:
+		backend = filehandler.NewFileHandler(w, file, bufferSize, enc)
+		json = enc
+	case config.FormatLogfmt:
+		backend = filehandler.NewLogfmtHandler(w, file, bufferSize, enc)
+	case config.FormatPrettyText, config.FormatPrettyJSON:
+		forced := *pretty
+		forced.IsJsonOutput = format == config.FormatPrettyJSON
+		backend = consolehandler.NewPrettyHandler(w, file, &forced, enc)
+	default:
+		backend = consolehandler.NewPrettyHandler(w, file, pretty, enc)
+	}
+
+	if cfg.Async.Enabled {
+		backend = newAsyncHandler(backend, cfg.Async, []slog.Attr{
+			slog.String("service", cfg.DefaultFields.Service),
+			slog.String("version", cfg.DefaultFields.Version),
+		})
+	}
+	return backend, json
+}
+
+// autoFormat picks JSON for regular files and pretty output for anything else
+func autoFormat(w io.Writer) string {
+	if isFileWriter(w) {
+		return config.FormatJSON
+	}
+	return config.FormatPretty
+}
+
+// isFileWriter reports whether w is a regular file rather than stdout/stderr
+func isFileWriter(w io.Writer) bool {
+	switch f := w.(type) {
+	case *os.File:
+		fd := f.Fd()
+		return fd != os.Stdout.Fd() && fd != os.Stderr.Fd()
+	case *filehandler.RotatingFile:
+		return true
+	}
+	return false
+}
+
+func parseLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// stackDepth returns how many frames the sink wants for a record of level
+func (s *sink) stackDepth(level slog.Level) int {
+	if !s.stack.Enabled {
+		return 0
+	}
+	switch level {
+	case slog.LevelError:
+		return s.stack.Depth.Error
+	case slog.LevelWarn:
+		return s.stack.Depth.Warn
+	case slog.LevelInfo:
+		return s.stack.Depth.Info
+	case slog.LevelDebug:
+		return s.stack.Depth.Debug
+	}
+	return 0
+}
+
+func (s *sink) wrapErr(err error) error {
+	if err == nil || s.name == "" {
+		return err
+	}
+	return fmt.Errorf("sink %s: %w", s.name, err)
+}
@@ -0,0 +1,49 @@
+// Package term inspects the terminal a writer is attached to
+package term
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// IsTerminal reports whether w is a file attached to a terminal
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+	return isTerminal(f.Fd())
+}
+
+// ColorEnabled reports whether colors should be written to w in the auto
+// color mode: never when NO_COLOR is set, always when FORCE_COLOR is set to
+// anything but 0 or false, and otherwise only to a terminal.
+// See https://no-color.org and https://force-color.org.
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	switch os.Getenv("FORCE_COLOR") {
+	case "":
+	case "0", "false":
+		return false
+	default:
+		return true
+	}
+	return IsTerminal(w)
+}
+
+// Width returns the number of columns to lay output to w out in: COLUMNS when
+// it is set to a positive number, otherwise the width of the terminal w is
+// attached to. It returns 0 when the width is unknown.
+func Width(w io.Writer) int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return 0
+	}
+	return width(f.Fd())
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly || windows)
+
+package term
+
+// isTerminal is not supported on this platform, so colors stay off unless
+// forced
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func width(fd uintptr) int {
+	return 0
+}
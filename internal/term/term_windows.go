@@ -0,0 +1,30 @@
+package term
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetConsoleScreenBufferInfo = syscall.NewLazyDLL("kernel32.dll").NewProc("GetConsoleScreenBufferInfo")
+
+func isTerminal(fd uintptr) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(fd), &mode) == nil
+}
+
+// consoleScreenBufferInfo is CONSOLE_SCREEN_BUFFER_INFO
+type consoleScreenBufferInfo struct {
+	size, cursorPosition     struct{ x, y int16 }
+	attributes               uint16
+	left, top, right, bottom int16
+	maximumWindowSize        struct{ x, y int16 }
+}
+
+func width(fd uintptr) int {
+	var info consoleScreenBufferInfo
+	ok, _, _ := procGetConsoleScreenBufferInfo.Call(fd, uintptr(unsafe.Pointer(&info)))
+	if ok == 0 {
+		return 0
+	}
+	return int(info.right-info.left) + 1
+}
@@ -1,11 +1,18 @@
 package logger
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 
 	"github.com/aaffriya/logger/config"
+	"github.com/aaffriya/logger/internal/diag"
 	customhandler "github.com/aaffriya/logger/internal/handler"
+	"github.com/aaffriya/logger/internal/handler/console"
+	filehandler "github.com/aaffriya/logger/internal/handler/file"
 )
 
 func SetupConsolePrettyLogger(config *config.LoggerConfig, opts *slog.HandlerOptions) {
@@ -21,3 +28,133 @@ func SetupFileLogger(config *config.LoggerConfig, opts *slog.HandlerOptions, fil
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
 }
+
+// SetupRotatingFileLogger opens config.File.Path, rotates it according to
+// config.File.Rotation and prunes rotated files according to
+// config.File.Retention. The returned Closer drains queued records, closes
+// the active file and waits for background compression and cleanup.
+func SetupRotatingFileLogger(config *config.LoggerConfig, opts *slog.HandlerOptions) (io.Closer, error) {
+	file, err := filehandler.OpenRotatingFile(config.File)
+	if err != nil {
+		return nil, err
+	}
+	handler := customhandler.NewHandler(config, opts, file)
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+
+	return closerFunc(func() error {
+		closeErr := handler.(*customhandler.Handler).Close(context.Background())
+		if err := file.Close(); err != nil {
+			return err
+		}
+		return closeErr
+	}), nil
+}
+
+// SetupMultiLogger sets a default logger that writes every record to each of
+// config.Sinks whose level it passes, so console, file and alert outputs can
+// run side by side with their own level, format and stack settings. File
+// outputs are opened with the sink's rotation and retention settings; the
+// returned Closer drains queued records and closes them.
+func SetupMultiLogger(config *config.LoggerConfig, opts *slog.HandlerOptions) (io.Closer, error) {
+	sinks := make([]customhandler.Sink, 0, len(config.Sinks))
+	var files []*filehandler.RotatingFile
+	closeFiles := func() error {
+		var errs []error
+		for _, file := range files {
+			errs = append(errs, file.Close())
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, sc := range config.Sinks {
+		w, file, err := openSinkOutput(sc)
+		if err != nil {
+			closeFiles()
+			return nil, err
+		}
+		if file != nil {
+			files = append(files, file)
+		}
+		sinks = append(sinks, customhandler.Sink{Config: sc, Writer: w})
+	}
+
+	handler := customhandler.NewSinkHandler(config, opts, sinks...)
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+
+	return closerFunc(func() error {
+		closeErr := handler.(*customhandler.Handler).Close(context.Background())
+		return errors.Join(closeErr, closeFiles())
+	}), nil
+}
+
+func openSinkOutput(sc config.SinkConfig) (io.Writer, *filehandler.RotatingFile, error) {
+	switch sc.Output {
+	case config.OutputStdout:
+		return os.Stdout, nil, nil
+	case config.OutputStderr:
+		return os.Stderr, nil, nil
+	case "":
+		return nil, nil, fmt.Errorf("sink %q has no output", sc.Name)
+	}
+
+	file, err := filehandler.OpenRotatingFile(config.FileConfig{
+		Path:      sc.Output,
+		Rotation:  sc.Rotation,
+		Retention: sc.Retention,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, file, nil
+}
+
+// NewPrettyJSONWriter returns a writer that renders the NDJSON records of
+// any JSON producer, such as slog.JSONHandler or a subprocess, in the pretty
+// console layout of config. Close renders a last line left without a newline.
+func NewPrettyJSONWriter(w io.Writer, config *config.PrettyConfig) io.WriteCloser {
+	return console.NewPrettyJSONWriter(w, config)
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+// SetDiagnosticsLogger sets where the logger reports its own events, such as
+// rotated files removed by retention or failed compression. By default they
+// are written as text to stderr; nil restores the default.
+func SetDiagnosticsLogger(l *slog.Logger) {
+	diag.SetLogger(l)
+}
+
+// Flush blocks until every record logged through the default logger before
+// the call has been written, including records queued in async mode.
+func Flush(ctx context.Context) error {
+	if h, ok := slog.Default().Handler().(interface{ Flush(context.Context) error }); ok {
+		return h.Flush(ctx)
+	}
+	return nil
+}
+
+// Close drains the async queue of the default logger and flushes it. Call it
+// on shutdown; records logged afterwards are written synchronously. Files
+// opened by SetupRotatingFileLogger are closed through the returned Closer.
+func Close(ctx context.Context) error {
+	if h, ok := slog.Default().Handler().(interface{ Close(context.Context) error }); ok {
+		return h.Close(ctx)
+	}
+	return nil
+}
+
+// Reopen reopens the log file of the default logger. Call it after an
+// external tool such as logrotate renamed the file, so records stop going to
+// the orphaned inode. Loggers that do not write to a file ignore it.
+func Reopen() error {
+	if h, ok := slog.Default().Handler().(interface{ Reopen() error }); ok {
+		return h.Reopen()
+	}
+	return nil
+}
@@ -32,34 +32,22 @@ func FromContext(ctx context.Context) ContextData {
 		return ContextData{}
 	}
 
-	data := ContextData{}
-	allData := GetPair(ctx, TraceIDKey, SpanIDKey, TraceFlagsKey, UserIDKey, SessionIDKey, ActionKey, TokenKey)
-
-	if traceID, ok := allData[TraceIDKey]; ok {
-		data.TraceID = traceID
-	}
-	if spanID, ok := allData[SpanIDKey]; ok {
-		data.SpanID = spanID
-	}
-	if traceFlags, ok := allData[TraceFlagsKey]; ok {
-		data.TraceFlags = traceFlags
-	}
-	if userID, ok := allData[UserIDKey]; ok {
-		data.UserID = userID
-	}
-	if sessionID, ok := allData[SessionIDKey]; ok {
-		data.SessionID = sessionID
-	}
-	if action, ok := allData[ActionKey]; ok {
-		data.Action = action
-	}
-	if token, ok := allData[TokenKey]; ok {
-		data.Token = token
+	// Read the carriers directly, GetPair and GetData allocate for every log
+	// record
+	carrier, _ := ctx.Value(contextCarrierKey).(map[string]string)
+	data := ContextData{
+		TraceID:    carrier[TraceIDKey],
+		SpanID:     carrier[SpanIDKey],
+		TraceFlags: carrier[TraceFlagsKey],
+		UserID:     carrier[UserIDKey],
+		SessionID:  carrier[SessionIDKey],
+		Action:     carrier[ActionKey],
+		Token:      carrier[TokenKey],
 	}
 
 	// SessionData is stored separately as map[string]any
-	if sessionData, ok := GetData(ctx, SessionDataKey); ok {
-		if sessionMap, ok := sessionData.(map[string]any); ok {
+	if dataCarrier, ok := ctx.Value(contextDataCarrierKey).(map[string]any); ok {
+		if sessionMap, ok := dataCarrier[SessionDataKey].(map[string]any); ok {
 			data.SessionData = sessionMap
 		}
 	}
@@ -0,0 +1,97 @@
+package pretty
+
+import (
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultRules returns a copy of the rules a Registry applies after its own
+// unless DisableDefaults is called, for registering a subset of them
+func DefaultRules() []Rule {
+	return slices.Clone(defaultRules)
+}
+
+var defaultRules = []Rule{
+	{Name: "email", Type: TypeString, Color: ColorLink, Match: func(f Field) bool {
+		return f.Key == "email" || strings.Contains(f.Text, "@")
+	}},
+	{Name: "url", Type: TypeString, Color: ColorLink, Match: func(f Field) bool {
+		return f.Key == "url" || strings.HasPrefix(f.Text, "http://") || strings.HasPrefix(f.Text, "https://")
+	}},
+	{Name: "ip", Type: TypeString, Color: ColorAddress, Match: func(f Field) bool {
+		return f.Key == "ip" || f.Key == "ip_address" || net.ParseIP(f.Text) != nil
+	}},
+	{Name: "error", Color: ColorBad, Match: func(f Field) bool {
+		return f.Type() == TypeError || f.Key == "error" || f.Key == "failure"
+	}},
+	{Name: "failure", Type: TypeString, Color: ColorBad, Pattern: wordPattern("error", "errors", "failed", "failure", "fatal")},
+	{Name: "success", Type: TypeString, Color: ColorGood, Pattern: wordPattern("ok", "success", "successful", "succeeded", "completed")},
+	{Name: "warning", Type: TypeString, Color: ColorWarning, Pattern: wordPattern("warning", "timeout", "retry", "retrying")},
+	{Name: "path", Type: TypeString, Color: ColorPath, Match: func(f Field) bool {
+		return strings.Contains(f.Text, "/") && (strings.Contains(f.Text, ".go") || strings.Contains(f.Text, ".js") || strings.Contains(f.Text, ".py"))
+	}},
+	{Name: "database", Type: TypeString, Color: ColorDatabase, Match: func(f Field) bool {
+		return f.Key == "database" || f.Key == "table" || f.Key == "query"
+	}},
+	{Name: "duration", Type: TypeDuration, Format: func(f Field) Result {
+		return Result{Color: thresholdColor(float64(f.Value.Duration()), float64(100*time.Millisecond), float64(time.Second))}
+	}},
+	{Name: "duration text", Type: TypeString, Color: ColorDuration, Match: func(f Field) bool {
+		if f.Key == "duration" || f.Key == "timeout" {
+			return true
+		}
+		_, err := time.ParseDuration(f.Text)
+		return err == nil
+	}},
+	{Name: "status", Type: TypeNumber, Format: func(f Field) Result {
+		n, _ := f.Float()
+		switch {
+		case n >= 200 && n < 300:
+			return Result{Color: ColorGood}
+		case n >= 400 && n < 500:
+			return Result{Color: ColorWarning}
+		case n >= 500:
+			return Result{Color: ColorBad}
+		}
+		return Result{Color: ColorNumber}
+	}, Match: keyIn("status", "code", "status_code")},
+	// Plain numbers are taken to be milliseconds
+	{Name: "latency", Type: TypeNumber, Format: func(f Field) Result {
+		n, _ := f.Float()
+		return Result{Color: thresholdColor(n, 100, 1000)}
+	}, Match: keyIn("duration", "latency", "response_time")},
+	{Name: "size", Type: TypeNumber, Format: func(f Field) Result {
+		n, _ := f.Float()
+		return Result{Color: thresholdColor(n, 1024*1024, 100*1024*1024)}
+	}, Match: keyIn("memory", "size", "bytes")},
+	{Name: "retries", Type: TypeNumber, Format: func(f Field) Result {
+		n, _ := f.Float()
+		return Result{Color: thresholdColor(n, 1, 3)}
+	}, Match: keyIn("retry", "retry_count", "attempts")},
+	{Name: "port", Key: "port", Type: TypeNumber, Color: ColorAddress},
+}
+
+// wordPattern matches any of words as a whole word, ignoring case
+func wordPattern(words ...string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(words, "|") + `)\b`)
+}
+
+func keyIn(keys ...string) func(Field) bool {
+	return func(f Field) bool {
+		return slices.Contains(keys, f.Key)
+	}
+}
+
+// thresholdColor is good below low, a warning below high and bad above
+func thresholdColor(n, low, high float64) string {
+	switch {
+	case n < low:
+		return ColorGood
+	case n < high:
+		return ColorWarning
+	}
+	return ColorBad
+}
@@ -0,0 +1,218 @@
+// Package pretty customizes how the console formats display attribute values.
+// Rules match attributes by key, key glob, value type or a regular expression
+// on the value text, and pick a color and optionally a display string.
+//
+//	rules := pretty.NewRegistry()
+//	rules.Register(pretty.Rule{KeyGlob: "*_ms", Type: pretty.TypeNumber, Format: func(f pretty.Field) pretty.Result {
+//		return pretty.Result{Display: f.Text + "ms"}
+//	}})
+//	loggerConfig.Pretty.Rules = rules
+package pretty
+
+import (
+	"log/slog"
+	"path"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Colors a rule can pick, resolved by the handler's theme, see
+// config.ValueColors. Any other color is read as a color definition such as
+// "bold #ff8700".
+const (
+	ColorString   = "string"
+	ColorNumber   = "number"
+	ColorTrue     = "true"
+	ColorFalse    = "false"
+	ColorObject   = "object"
+	ColorArray    = "array"
+	ColorLink     = "link"
+	ColorAddress  = "address"
+	ColorGood     = "good"
+	ColorWarning  = "warning"
+	ColorBad      = "bad"
+	ColorPath     = "path"
+	ColorDatabase = "database"
+	ColorDuration = "duration"
+)
+
+// ValueType is the type of an attribute value as seen by rules
+type ValueType string
+
+const (
+	TypeString   ValueType = "string"
+	TypeNumber   ValueType = "number" // signed, unsigned and floating point
+	TypeBool     ValueType = "bool"
+	TypeDuration ValueType = "duration"
+	TypeTime     ValueType = "time"
+	TypeError    ValueType = "error"
+	TypeAny      ValueType = "any" // any other value
+)
+
+// Field is an attribute value about to be displayed
+type Field struct {
+	Key   string
+	Value slog.Value // resolved, never a group
+	Text  string     // the value as it would be displayed
+}
+
+// Type returns the ValueType of the field value
+func (f Field) Type() ValueType {
+	switch f.Value.Kind() {
+	case slog.KindString:
+		return TypeString
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64:
+		return TypeNumber
+	case slog.KindBool:
+		return TypeBool
+	case slog.KindDuration:
+		return TypeDuration
+	case slog.KindTime:
+		return TypeTime
+	}
+	if _, ok := f.Value.Any().(error); ok {
+		return TypeError
+	}
+	return TypeAny
+}
+
+// Float returns numeric values as a float64 and durations in milliseconds
+func (f Field) Float() (float64, bool) {
+	switch f.Value.Kind() {
+	case slog.KindInt64:
+		return float64(f.Value.Int64()), true
+	case slog.KindUint64:
+		return float64(f.Value.Uint64()), true
+	case slog.KindFloat64:
+		return f.Value.Float64(), true
+	case slog.KindDuration:
+		return float64(f.Value.Duration()) / float64(time.Millisecond), true
+	}
+	return 0, false
+}
+
+// Result is what a rule does to a field
+type Result struct {
+	Color   string // empty keeps the color of the value type
+	Display string // empty keeps the value text
+}
+
+// Rule matches fields on every condition that is set. For each field, the
+// first registered rule that matches it wins. A rule without conditions
+// matches every field.
+type Rule struct {
+	Name    string         // shown in errors only
+	Key     string         // exact key
+	KeyGlob string         // key pattern in path.Match syntax, such as "*_ms"
+	Type    ValueType      // value type
+	Pattern *regexp.Regexp // tested against the value text
+	Match   func(Field) bool
+
+	Color  string             // color of matching fields
+	Format func(Field) Result // computes the result, a returned color takes precedence over Color
+}
+
+func (rule *Rule) matches(f Field) bool {
+	if rule.Key != "" && rule.Key != f.Key {
+		return false
+	}
+	if rule.KeyGlob != "" {
+		if ok, _ := path.Match(rule.KeyGlob, f.Key); !ok {
+			return false
+		}
+	}
+	if rule.Type != "" && rule.Type != f.Type() {
+		return false
+	}
+	if rule.Pattern != nil && !rule.Pattern.MatchString(f.Text) {
+		return false
+	}
+	return rule.Match == nil || rule.Match(f)
+}
+
+func (rule *Rule) apply(f Field) Result {
+	result := Result{Color: rule.Color}
+	if rule.Format != nil {
+		formatted := rule.Format(f)
+		if formatted.Color != "" {
+			result.Color = formatted.Color
+		}
+		result.Display = formatted.Display
+	}
+	return result
+}
+
+// Registry holds the rules of one or more handlers. Registered rules are
+// tried in order before the default rules. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	rules    []Rule
+	defaults bool
+}
+
+// NewRegistry returns a registry with the default rules enabled
+func NewRegistry() *Registry {
+	return &Registry{defaults: true}
+}
+
+// Register adds rules after the ones already registered
+func (r *Registry) Register(rules ...Rule) error {
+	for _, rule := range rules {
+		if rule.KeyGlob != "" {
+			if _, err := path.Match(rule.KeyGlob, ""); err != nil {
+				return &RuleError{Rule: rule.Name, Err: err}
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = append(r.rules, rules...)
+	return nil
+}
+
+// DisableDefaults turns the default rules off, leaving only the registered
+// ones
+func (r *Registry) DisableDefaults() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaults = false
+}
+
+// Apply returns the result of the first rule matching f
+func (r *Registry) Apply(f Field) (Result, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := range r.rules {
+		if r.rules[i].matches(f) {
+			return r.rules[i].apply(f), true
+		}
+	}
+	if r.defaults {
+		for i := range defaultRules {
+			if defaultRules[i].matches(f) {
+				return defaultRules[i].apply(f), true
+			}
+		}
+	}
+	return Result{}, false
+}
+
+// RuleError reports a rule that cannot be registered
+type RuleError struct {
+	Rule string
+	Err  error
+}
+
+func (e *RuleError) Error() string {
+	if e.Rule == "" {
+		return "pretty: invalid rule: " + e.Err.Error()
+	}
+	return "pretty: invalid rule " + e.Rule + ": " + e.Err.Error()
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
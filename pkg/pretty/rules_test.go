@@ -0,0 +1,102 @@
+package pretty
+
+import (
+	"errors"
+	"log/slog"
+	"path"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func field(key string, value any) Field {
+	v := slog.AnyValue(value)
+	return Field{Key: key, Value: v, Text: v.String()}
+}
+
+func TestRuleConditions(t *testing.T) {
+	r := NewRegistry()
+	r.DisableDefaults()
+	err := r.Register(
+		Rule{Name: "key", Key: "user", Color: "key"},
+		Rule{Name: "glob", KeyGlob: "*_ms", Type: TypeNumber, Color: "glob"},
+		Rule{Name: "type", Type: TypeDuration, Color: "type"},
+		Rule{Name: "pattern", Pattern: regexp.MustCompile(`^v\d+$`), Color: "pattern"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		field Field
+		color string
+	}{
+		{field("user", "ana"), "key"},
+		{field("db_ms", 12), "glob"},
+		{field("db_ms", "12"), ""},
+		{field("elapsed", time.Second), "type"},
+		{field("release", "v2"), "pattern"},
+		{field("release", "v2.1"), ""},
+	}
+	for _, c := range cases {
+		result, ok := r.Apply(c.field)
+		if ok != (c.color != "") || result.Color != c.color {
+			t.Errorf("%s=%s: expected color %q, got %q (matched %v)", c.field.Key, c.field.Text, c.color, result.Color, ok)
+		}
+	}
+}
+
+func TestRuleOrderAndFormat(t *testing.T) {
+	r := NewRegistry()
+	r.Register(
+		Rule{Key: "status", Color: "first", Format: func(f Field) Result {
+			return Result{Display: "HTTP " + f.Text}
+		}},
+		Rule{Key: "status", Color: "second"},
+	)
+
+	result, ok := r.Apply(field("status", 503))
+	if !ok || result.Color != "first" || result.Display != "HTTP 503" {
+		t.Errorf("Expected the first registered rule to apply, got %+v", result)
+	}
+}
+
+func TestDefaultRules(t *testing.T) {
+	r := NewRegistry()
+	cases := []struct {
+		field Field
+		color string
+	}{
+		{field("status", 503), ColorBad},
+		{field("status", 204), ColorGood},
+		{field("result", "ok"), ColorGood},
+		{field("token", "xyzok123"), ""},
+		{field("err", errors.New("boom")), ColorBad},
+		{field("elapsed", 2*time.Second), ColorBad},
+		{field("url", "https://example.com"), ColorLink},
+	}
+	for _, c := range cases {
+		result, _ := r.Apply(c.field)
+		if result.Color != c.color {
+			t.Errorf("%s=%s: expected color %q, got %q", c.field.Key, c.field.Text, c.color, result.Color)
+		}
+	}
+
+	r.DisableDefaults()
+	if _, ok := r.Apply(field("status", 503)); ok {
+		t.Error("Expected no rule to match with the defaults disabled")
+	}
+}
+
+func TestRegisterInvalidGlob(t *testing.T) {
+	r := NewRegistry()
+	err := r.Register(Rule{Name: "broken", KeyGlob: "[a"})
+
+	var ruleErr *RuleError
+	if !errors.As(err, &ruleErr) || ruleErr.Rule != "broken" || !errors.Is(err, path.ErrBadPattern) {
+		t.Fatalf("Expected a RuleError wrapping path.ErrBadPattern, got %v", err)
+	}
+	if _, ok := r.Apply(field("a", "x")); ok {
+		t.Error("Expected the invalid rule not to be registered")
+	}
+}
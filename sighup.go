@@ -0,0 +1,42 @@
+//go:build unix || windows
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/aaffriya/logger/internal/diag"
+)
+
+// ReopenOnSIGHUP calls Reopen every time the process receives SIGHUP, the
+// signal logrotate sends from a postrotate script. Failures are reported
+// through the diagnostics logger. The returned function stops listening.
+func ReopenOnSIGHUP() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				if err := Reopen(); err != nil {
+					diag.Error("failed to reopen log file", "error", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
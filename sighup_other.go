@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package logger
+
+// ReopenOnSIGHUP does nothing on platforms without SIGHUP. The returned
+// function does nothing either.
+func ReopenOnSIGHUP() (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,38 @@
+//go:build !race
+
+// The race detector drops pooled values at random, so allocations are only
+// counted without it
+
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+)
+
+func TestJSONHandlerAllocations(t *testing.T) {
+	cfg := newFormatTestConfig(config.FormatJSON)
+	logger := slog.New(customhandler.NewHandler(cfg, nil, io.Discard))
+	derived := logger.With("request_id", "req-1").WithGroup("request")
+	ctx := context.Background()
+	started := time.Now()
+
+	for name, log := range map[string]func(){
+		"record": func() {
+			logger.LogAttrs(ctx, slog.LevelInfo, "Request handled", benchmarkAttrs...)
+		},
+		"with attrs": func() {
+			derived.LogAttrs(ctx, slog.LevelInfo, "Request handled", slog.Time("started", started), slog.Int("status", 200))
+		},
+	} {
+		if allocs := testing.AllocsPerRun(100, log); allocs != 0 {
+			t.Errorf("%s: expected no allocations per record, got %v", name, allocs)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+	filehandler "github.com/aaffriya/logger/internal/handler/file"
+)
+
+type asyncTestHandler interface {
+	slog.Handler
+	Flush(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+// slowWriter simulates a slow sink such as a congested disk or pipe
+type slowWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	delay time.Duration
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func newAsyncTestConfig(async config.AsyncConfig) *config.LoggerConfig {
+	return &config.LoggerConfig{
+		DefaultFields: config.DefaultFieldInfo{
+			Version: "v1.0.0",
+			Service: "AsyncTest",
+		},
+		Stack: config.StackConfig{
+			Enabled: false,
+		},
+		Async: async,
+	}
+}
+
+func TestAsyncConsoleFlush(t *testing.T) {
+	writer := newGateWriter()
+	handler := customhandler.NewHandler(newAsyncTestConfig(config.AsyncConfig{Enabled: true}), nil, writer).(asyncTestHandler)
+	logger := slog.New(handler)
+
+	// The writer blocks until released, so logging only returns if the
+	// records were queued
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		for i := 0; i < 50; i++ {
+			logger.Info("Async console message", "index", i)
+		}
+	}()
+	select {
+	case <-logged:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected logging not to wait on the blocked writer")
+	}
+	if writer.String() != "" {
+		t.Errorf("Expected nothing written while the writer is blocked, got: %s", writer.String())
+	}
+	close(writer.release)
+
+	if err := handler.Flush(context.Background()); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+
+	output := writer.String()
+	if count := strings.Count(output, "Async console message"); count != 50 {
+		t.Errorf("Expected 50 records after Flush, got %d", count)
+	}
+	if strings.Index(output, "index=0") > strings.Index(output, "index=49") {
+		t.Error("Expected records to keep their order")
+	}
+	handler.Close(context.Background())
+}
+
+func TestAsyncSinksKeepTheirAttributes(t *testing.T) {
+	var jsonOut, logfmtOut bytes.Buffer
+	cfg := newAsyncTestConfig(config.AsyncConfig{Enabled: true, FlushInterval: time.Hour})
+	handler := customhandler.NewSinkHandler(cfg, nil,
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatJSON}, Writer: &jsonOut},
+		customhandler.Sink{Config: config.SinkConfig{
+			Format: config.FormatLogfmt,
+			Stack:  &config.StackConfig{Enabled: true, Depth: config.StackDepths{Info: 2}},
+		}, Writer: &logfmtOut},
+	).(asyncTestHandler)
+
+	slog.New(handler).Info("Queued", "index", 1)
+	if err := handler.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	records := decodeJSONLines(t, jsonOut.String())
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 JSON record, got: %s", jsonOut.String())
+	}
+	if _, ok := records[0]["trace"]; ok || records[0]["service"] != "AsyncTest" || records[0]["index"] != float64(1) {
+		t.Errorf("Expected the JSON record with its own attributes, got %v", records[0])
+	}
+	if !strings.Contains(logfmtOut.String(), "trace=") || !strings.Contains(logfmtOut.String(), "service=AsyncTest") {
+		t.Errorf("Expected the logfmt record with a trace, got: %s", logfmtOut.String())
+	}
+}
+
+func TestAsyncFileBufferingAndClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create log file: %v", err)
+	}
+	defer file.Close()
+
+	cfg := newAsyncTestConfig(config.AsyncConfig{Enabled: true, QueueSize: 4, FlushInterval: time.Hour})
+	handler := customhandler.NewHandler(cfg, nil, file).(asyncTestHandler)
+	logger := slog.New(handler)
+
+	for i := 0; i < 100; i++ {
+		logger.Info("Buffered message", "index", i)
+	}
+
+	if err := handler.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	if got := len(readJSONLines(t, path)); got != 100 {
+		t.Errorf("Expected 100 records after Close, got %d", got)
+	}
+
+	logger.Info("After close")
+	if got := len(readJSONLines(t, path)); got != 101 {
+		t.Errorf("Expected records after Close to be written synchronously, got %d", got)
+	}
+}
+
+func TestAsyncFlushInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := filehandler.OpenRotatingFile(config.FileConfig{Path: path})
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	defer file.Close()
+
+	cfg := newAsyncTestConfig(config.AsyncConfig{Enabled: true, FlushInterval: 20 * time.Millisecond})
+	handler := customhandler.NewHandler(cfg, nil, file).(asyncTestHandler)
+	defer handler.Close(context.Background())
+
+	slog.New(handler).Info("Flushed by the interval")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(readJSONLines(t, path)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the record to be flushed by the flush interval")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAsyncFlushRespectsContext(t *testing.T) {
+	writer := &slowWriter{delay: 50 * time.Millisecond}
+	handler := customhandler.NewHandler(newAsyncTestConfig(config.AsyncConfig{Enabled: true}), nil, writer).(asyncTestHandler)
+	logger := slog.New(handler)
+
+	for i := 0; i < 10; i++ {
+		logger.Info("Slow message")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := handler.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected Flush to give up with the context, got %v", err)
+	}
+
+	if err := handler.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	if count := strings.Count(writer.String(), "Slow message"); count != 10 {
+		t.Errorf("Expected Close to drain all 10 records, got %d", count)
+	}
+}
+
+// gateWriter blocks every write until released, so a test can fill the queue
+type gateWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return stripANSI(w.buf.String())
+}
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+func TestBackpressureDropNewestKeepsErrors(t *testing.T) {
+	writer := newGateWriter()
+	cfg := newAsyncTestConfig(config.AsyncConfig{
+		Enabled:   true,
+		QueueSize: 4,
+		Backpressure: config.BackpressureConfig{
+			Debug: config.BackpressureDropNewest,
+			Error: config.BackpressureBlock,
+		},
+	})
+	cfg.Level = "debug"
+	handler := customhandler.NewHandler(cfg, nil, writer).(asyncTestHandler)
+	logger := slog.New(handler)
+
+	// The first record occupies the writer, the next four fill the queue
+	logger.Info("Occupies the writer")
+	<-writer.started
+	for i := 0; i < 14; i++ {
+		logger.Debug("Debug under pressure", "index", i)
+	}
+
+	errorsDone := make(chan struct{})
+	go func() {
+		defer close(errorsDone)
+		for i := 0; i < 5; i++ {
+			logger.Error("Error under pressure", "index", i)
+		}
+	}()
+
+	close(writer.release)
+	<-errorsDone
+	if err := handler.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	output := writer.String()
+	if count := strings.Count(output, "Error under pressure"); count != 5 {
+		t.Errorf("Expected all 5 error records, got %d", count)
+	}
+	if count := strings.Count(output, "Debug under pressure"); count != 4 {
+		t.Errorf("Expected 4 debug records to fit in the queue, got %d", count)
+	}
+	if !strings.Contains(output, "logs dropped") || !strings.Contains(output, "dropped_debug=10") {
+		t.Errorf("Expected a logs dropped record counting 10 debug records, got: %s", output)
+	}
+	if strings.Contains(output, "dropped_error") {
+		t.Errorf("Expected no error records to be dropped, got: %s", output)
+	}
+}
+
+func TestBackpressureDropOldestSkipsBlockingLevels(t *testing.T) {
+	writer := newGateWriter()
+	cfg := newAsyncTestConfig(config.AsyncConfig{
+		Enabled:   true,
+		QueueSize: 4,
+		Backpressure: config.BackpressureConfig{
+			Debug: config.BackpressureDropOldest,
+		},
+	})
+	cfg.Level = "debug"
+	handler := customhandler.NewHandler(cfg, nil, writer).(asyncTestHandler)
+	logger := slog.New(handler)
+
+	logger.Info("Occupies the writer")
+	<-writer.started
+	logger.Debug("debug-a")
+	logger.Error("error-b")
+	logger.Debug("debug-c")
+	logger.Error("error-d")
+	logger.Debug("debug-e") // evicts debug-a
+	logger.Debug("debug-f") // evicts debug-c
+
+	close(writer.release)
+	if err := handler.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	output := writer.String()
+	for _, kept := range []string{"error-b", "error-d", "debug-e", "debug-f"} {
+		if !strings.Contains(output, kept) {
+			t.Errorf("Expected %s to be written, got: %s", kept, output)
+		}
+	}
+	for _, evicted := range []string{"debug-a", "debug-c"} {
+		if strings.Contains(output, evicted) {
+			t.Errorf("Expected %s to be evicted, got: %s", evicted, output)
+		}
+	}
+	if !strings.Contains(output, "dropped_debug=2") {
+		t.Errorf("Expected the report to count 2 dropped debug records, got: %s", output)
+	}
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+)
+
+var benchmarkAttrs = []slog.Attr{
+	slog.String("method", "GET"),
+	slog.String("path", "/api/v1/orders"),
+	slog.Int("status", 200),
+	slog.Duration("elapsed", 1500*time.Microsecond),
+	slog.Bool("cached", true),
+}
+
+func benchmarkHandlers() map[string]slog.Handler {
+	return map[string]slog.Handler{
+		"logger": customhandler.NewHandler(newFormatTestConfig(config.FormatJSON), nil, io.Discard),
+		"slog":   slog.NewJSONHandler(io.Discard, nil),
+	}
+}
+
+func BenchmarkJSONHandler(b *testing.B) {
+	for name, handler := range benchmarkHandlers() {
+		b.Run(name, func(b *testing.B) {
+			logger := slog.New(handler)
+			ctx := context.Background()
+			b.ReportAllocs()
+			for b.Loop() {
+				logger.LogAttrs(ctx, slog.LevelInfo, "Request handled", benchmarkAttrs...)
+			}
+		})
+	}
+}
+
+func BenchmarkJSONHandlerWithAttrs(b *testing.B) {
+	for name, handler := range benchmarkHandlers() {
+		b.Run(name, func(b *testing.B) {
+			logger := slog.New(handler.WithAttrs(benchmarkAttrs).WithGroup("request"))
+			ctx := context.Background()
+			b.ReportAllocs()
+			for b.Loop() {
+				logger.LogAttrs(ctx, slog.LevelInfo, "Request handled", slog.String("id", "req-1"))
+			}
+		})
+	}
+}
+
+// BenchmarkPrettyJSONLargePayload logs a record whose data is about 1 MB of
+// JSON through the whole pretty-json handler, encoding and indenting
+// included. BenchmarkAppendHighlightedJSON in the console package times the
+// highlighter alone.
+func BenchmarkPrettyJSONLargePayload(b *testing.B) {
+	rows := make([]map[string]any, 0, 8192)
+	for i := range cap(rows) {
+		rows = append(rows, map[string]any{"id": i, "name": "row", "score": -1.5e-3, "active": i%2 == 0, "note": nil})
+	}
+
+	cfg := newFormatTestConfig(config.FormatPrettyJSON)
+	cfg.Pretty = config.PrettyConfig{Color: config.ColorAlways}
+	logger := slog.New(customhandler.NewHandler(cfg, nil, io.Discard))
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.LogAttrs(ctx, slog.LevelInfo, "Export", slog.Any("rows", rows))
+	}
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+)
+
+func logColorTest(format, color string) string {
+	var buf bytes.Buffer
+	logger := newPrettyTestLogger(&buf, format, config.PrettyConfig{Color: color},
+		config.StackConfig{Enabled: true, Depth: config.StackDepths{Error: 2}})
+
+	logger.WithGroup("request").Error("Colors", "status", 500, slog.Group("db", "table", "users"))
+	return buf.String()
+}
+
+func TestColorModes(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+
+	for _, format := range []string{config.FormatPrettyText, config.FormatPrettyJSON} {
+		t.Run(format, func(t *testing.T) {
+			always := logColorTest(format, config.ColorAlways)
+			never := logColorTest(format, config.ColorNever)
+			auto := logColorTest(format, config.ColorAuto)
+
+			if !strings.Contains(always, "\x1b[") {
+				t.Errorf("Expected colors with always, got: %q", always)
+			}
+			if strings.Contains(never, "\x1b[") || strings.Contains(auto, "\x1b[") {
+				t.Errorf("Expected no colors with never or when writing to a buffer, got: %q and %q", never, auto)
+			}
+			if stripANSI(always) != never {
+				t.Errorf("Expected the same layout without colors, got:\n%s\nand:\n%s", stripANSI(always), never)
+			}
+		})
+	}
+}
+
+func TestColorEnvironment(t *testing.T) {
+	tests := []struct {
+		noColor, forceColor, mode string
+		want                      bool
+	}{
+		{"", "1", config.ColorAuto, true},
+		{"", "0", config.ColorAuto, false},
+		{"1", "1", config.ColorAuto, false},
+		{"1", "", config.ColorAlways, true},
+		{"", "1", config.ColorNever, false},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("NO_COLOR", tt.noColor)
+		t.Setenv("FORCE_COLOR", tt.forceColor)
+		if got := strings.Contains(logColorTest(config.FormatPrettyText, tt.mode), "\x1b["); got != tt.want {
+			t.Errorf("NO_COLOR=%q FORCE_COLOR=%q color=%q: expected colors %v, got %v", tt.noColor, tt.forceColor, tt.mode, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+)
+
+func newColumnsTestLogger(buf *bytes.Buffer) *slog.Logger {
+	return newPrettyTestLogger(buf, config.FormatPrettyText, config.PrettyConfig{Columns: true, Color: config.ColorNever})
+}
+
+func TestColumnsFirstLine(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	var buf bytes.Buffer
+	logger := newColumnsTestLogger(&buf)
+
+	logger.Info("Started", "action", "boot", "user_id", "u-1")
+	logger.Error("Failed")
+
+	lines := strings.Split(buf.String(), "\n")
+	want := []string{
+		"INFO  FormatTest/boot          Started | u-1",
+		"ERROR FormatTest               Failed",
+	}
+	if lines[0] != want[0] || lines[1] != want[1] {
+		t.Errorf("Expected %q, got %q", want, lines[:2])
+	}
+}
+
+func TestColumnsTruncateSource(t *testing.T) {
+	var buf bytes.Buffer
+	newColumnsTestLogger(&buf).Info("Started", "action", "a-very-long-action-name")
+
+	if line := strings.Split(buf.String(), "\n")[0]; line != "INFO  FormatTest/a-very-long-… Started" {
+		t.Errorf("Expected the source column to be truncated, got %q", line)
+	}
+}
+
+func TestColumnsWrapToWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "40")
+	var buf bytes.Buffer
+	logger := newColumnsTestLogger(&buf)
+
+	logger.Info("Wrapped", "query", "select id, name from users where active order by name")
+
+	want := "INFO  FormatTest               Wrapped\n" +
+		"  query=select id, name from users\n" +
+		"        where active order by name\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestColumnsNoWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	var buf bytes.Buffer
+	value := strings.Repeat("x", 200)
+	newColumnsTestLogger(&buf).Info("Unwrapped", "value", value)
+
+	if !strings.Contains(buf.String(), "  value="+value+"\n") {
+		t.Errorf("Expected no wrapping without a known width, got %q", buf.String())
+	}
+}
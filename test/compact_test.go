@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+)
+
+func newCompactTestLogger(buf *bytes.Buffer, color string) *slog.Logger {
+	return newPrettyTestLogger(buf, config.FormatPrettyText, config.PrettyConfig{Compact: true, Color: color})
+}
+
+func TestCompactSingleLine(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newCompactTestLogger(&buf, config.ColorNever)
+
+	logger.Info("Request served", "user_id", "u-1", "status", 200,
+		"path", "/users", slog.Group("db", "rows", 3), "note", "two words", "body", "a\nb", "empty", "")
+
+	want := `[INFO] Request served | u-1 status=200 path=/users db.rows=3 note="two words" body="a\nb" empty=""` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestCompactColors(t *testing.T) {
+	var plain, colored bytes.Buffer
+	newCompactTestLogger(&plain, config.ColorNever).Info("Colored", "status", 200, "note", "two words")
+	newCompactTestLogger(&colored, config.ColorAlways).Info("Colored", "status", 200, "note", "two words")
+
+	if stripANSI(colored.String()) != plain.String() {
+		t.Errorf("Expected colors to keep the layout, got %q and %q", colored.String(), plain.String())
+	}
+	if !strings.Contains(colored.String(), "\x1b[32m200") {
+		t.Errorf("Expected colored values, got %q", colored.String())
+	}
+}
+
+func TestCompactStackTrace(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newPrettyTestLogger(&buf, config.FormatPrettyText, config.PrettyConfig{Compact: true, Color: config.ColorNever},
+		config.StackConfig{Enabled: true, Depth: config.StackDepths{Error: 5}})
+
+	logger.Error("Failed", "attempt", 2)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) < 3 || lines[0] != "[ERROR] Failed attempt=2" || lines[1] != "Stack Trace:" {
+		t.Fatalf("Expected the record on one line followed by the stack trace, got %q", buf.String())
+	}
+}
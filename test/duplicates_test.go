@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+	ctxmeta "github.com/aaffriya/logger/pkg/context"
+)
+
+func newDuplicatesTestLogger(policy string, buf *bytes.Buffer) *slog.Logger {
+	cfg := newFormatTestConfig(config.FormatJSON)
+	cfg.DuplicateKeys = policy
+	cfg.Stack = config.StackConfig{Enabled: true, Depth: config.StackDepths{Error: 2}}
+	return slog.New(customhandler.NewHandler(cfg, nil, buf))
+}
+
+func TestDuplicateKeyPolicies(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   map[string]any
+	}{
+		{"", map[string]any{"user": "record"}},
+		{config.DuplicateKeepLast, map[string]any{"user": "record"}},
+		{config.DuplicateKeepFirst, map[string]any{"user": "with"}},
+		{config.DuplicateRename, map[string]any{"user": "with", "user_2": "record", "user_3": "again"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newDuplicatesTestLogger(tt.policy, &buf)
+
+			logger.With("user", "with").Info("Duplicated", "user", "record", "user", "again")
+			if tt.policy != config.DuplicateRename {
+				buf.Reset()
+				logger.With("user", "with").Info("Duplicated", "user", "record")
+			}
+
+			line := buf.String()
+			if strings.Count(line, `"user"`) != 1 {
+				t.Errorf("Expected the user key once, got: %s", line)
+			}
+			record := decodeJSONLines(t, line)[0]
+			for key, value := range tt.want {
+				if record[key] != value {
+					t.Errorf("Expected %s to be %v, got: %s", key, value, line)
+				}
+			}
+		})
+	}
+}
+
+func TestDuplicateKeysWithinGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newDuplicatesTestLogger(config.DuplicateKeepLast, &buf)
+
+	logger.WithGroup("request").With("id", "first").Info("Grouped", "id", "second", slog.Group("db", "table", "a", "table", "b"))
+
+	line := buf.String()
+	if !strings.Contains(line, `"request":{"id":"second","db":{"table":"b"}}`) {
+		t.Errorf("Expected the last attribute within each group, got: %s", line)
+	}
+}
+
+func TestReservedKeysCannotBeClobbered(t *testing.T) {
+	for _, policy := range []string{config.DuplicateKeepLast, config.DuplicateKeepFirst, config.DuplicateRename} {
+		t.Run(policy, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newDuplicatesTestLogger(policy, &buf)
+
+			logger.With("service", "fake").Error("Reserved", "trace", "mine", "level", "custom")
+
+			line := buf.String()
+			for _, key := range []string{`"trace"`, `"service"`, `"level"`} {
+				if strings.Count(line, key) != 1 {
+					t.Errorf("Expected %s once, got: %s", key, line)
+				}
+			}
+			record := decodeJSONLines(t, line)[0]
+			if _, ok := record["trace"].([]any); !ok || record["service"] != "FormatTest" || record["level"] != "ERROR" {
+				t.Errorf("Expected the logger's own fields to be kept, got: %s", line)
+			}
+
+			renamed := record["trace_2"] == "mine" && record["service_2"] == "fake" && record["level_2"] == "custom"
+			if policy == config.DuplicateKeepFirst && strings.Contains(line, "_2") {
+				t.Errorf("Expected clashing attributes to be dropped, got: %s", line)
+			}
+			if policy != config.DuplicateKeepFirst && !renamed {
+				t.Errorf("Expected clashing attributes to be renamed, got: %s", line)
+			}
+		})
+	}
+}
+
+func TestContextKeysProtectedFromWithAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newDuplicatesTestLogger(config.DuplicateKeepLast, &buf)
+	ctx := ctxmeta.WithUserID(context.Background(), "u-1")
+
+	logger.With("user_id", "attr").InfoContext(ctx, "Context")
+
+	record := decodeJSONLines(t, buf.String())[0]
+	if record["user_id"] != "u-1" || record["user_id_2"] != "attr" {
+		t.Errorf("Expected the context user_id kept and the attribute renamed, got: %s", buf.String())
+	}
+}
+
+func TestConsoleAttributeOrder(t *testing.T) {
+	for _, format := range []string{config.FormatPrettyText, config.FormatPrettyJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(customhandler.NewHandler(newFormatTestConfig(format), nil, &buf))
+
+			logger.With("zeta", 1).Info("Ordered", "mid", 2, "alpha", 3, "beta", 4)
+
+			output := stripANSI(buf.String())
+			last := -1
+			for _, key := range []string{"zeta", "mid", "alpha", "beta"} {
+				i := strings.Index(output, key)
+				if i <= last {
+					t.Fatalf("Expected attributes in insertion order, got: %s", output)
+				}
+				last = i
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+)
+
+type secret string
+
+func (s secret) LogValue() slog.Value {
+	return slog.StringValue("[redacted]")
+}
+
+type point struct {
+	X, Y float64
+}
+
+func TestJSONValueEncoding(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatJSON), nil, &buf))
+
+	logger.Info("Encoded values",
+		"error", errors.New("connection refused"),
+		"elapsed", 1500*time.Millisecond,
+		"token", secret("abc123"),
+		"ip", net.ParseIP("10.0.0.1"),
+		"ratio", math.NaN(),
+		"limit", math.Inf(1),
+		"point", point{X: math.Inf(-1)},
+	)
+
+	records := decodeJSONLines(t, buf.String())
+	if len(records) != 1 {
+		t.Fatalf("Expected the record to be written despite non-finite floats, got: %s", buf.String())
+	}
+	want := map[string]any{
+		"error":   "connection refused",
+		"elapsed": "1.5s",
+		"token":   "[redacted]",
+		"ip":      "10.0.0.1",
+		"ratio":   "NaN",
+		"limit":   "+Inf",
+	}
+	for key, value := range want {
+		if records[0][key] != value {
+			t.Errorf("Expected %s to be %v, got %v", key, value, records[0][key])
+		}
+	}
+	if point, _ := records[0]["point"].(string); !strings.Contains(point, "-Inf") {
+		t.Errorf("Expected an unencodable struct to fall back to text, got %v", records[0]["point"])
+	}
+}
+
+func TestConfigurableDurationAndTimeEncoding(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := newFormatTestConfig(config.FormatJSON)
+	cfg.Encoding = config.EncodingConfig{Duration: config.DurationMillis, Time: config.TimeUnix}
+	logger := slog.New(customhandler.NewHandler(cfg, nil, &buf))
+
+	logger.Info("Encoded values", "elapsed", 1500*time.Millisecond, "started", time.Unix(1700000000, 0))
+
+	records := decodeJSONLines(t, buf.String())
+	if records[0]["elapsed"] != float64(1500) || records[0]["started"] != float64(1700000000) {
+		t.Errorf("Expected millis and unix seconds, got %v", records[0])
+	}
+}
+
+func TestJSONDurationText(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatJSON), nil, &buf))
+
+	durations := []time.Duration{0, 1, -1500, 999 * time.Microsecond, 1500 * time.Millisecond, 61 * time.Second, -3*time.Hour - time.Nanosecond, math.MinInt64}
+	for _, d := range durations {
+		logger.Info("Duration", "elapsed", d)
+	}
+
+	for i, record := range decodeJSONLines(t, buf.String()) {
+		if want := durations[i].String(); record["elapsed"] != want {
+			t.Errorf("Expected %q, got %v", want, record["elapsed"])
+		}
+	}
+}
+
+func TestValueEncodingSharedByFormats(t *testing.T) {
+	var logfmt, text bytes.Buffer
+	handler := customhandler.NewSinkHandler(newFormatTestConfig(""), nil,
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatLogfmt}, Writer: &logfmt},
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatPrettyText}, Writer: &text},
+	)
+
+	slog.New(handler).Info("Encoded values", "elapsed", 1500*time.Millisecond, "token", secret("abc123"), "ratio", math.NaN())
+
+	for name, output := range map[string]string{"logfmt": logfmt.String(), "console": stripANSI(text.String())} {
+		for _, want := range []string{"elapsed=1.5s", "token=[redacted]", "ratio=NaN"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Expected %s output to contain %s, got: %s", name, want, output)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+)
+
+func newFormatTestConfig(format string) *config.LoggerConfig {
+	return &config.LoggerConfig{
+		DefaultFields: config.DefaultFieldInfo{
+			Version: "v1.0.0",
+			Service: "FormatTest",
+		},
+		Format: format,
+	}
+}
+
+// newPrettyTestLogger returns a logger writing format to w with the pretty
+// settings, and stack traces when a stack configuration is given
+func newPrettyTestLogger(w io.Writer, format string, pretty config.PrettyConfig, stack ...config.StackConfig) *slog.Logger {
+	cfg := newFormatTestConfig(format)
+	cfg.Pretty = pretty
+	if len(stack) > 0 {
+		cfg.Stack = stack[0]
+	}
+	return slog.New(customhandler.NewHandler(cfg, nil, w))
+}
+
+func TestJSONFormatToBuffer(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatJSON), nil, &buf))
+
+	logger.Info("JSON to a buffer", "count", 3)
+
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("Expected no ANSI codes in JSON output, got: %q", buf.String())
+	}
+	records := decodeJSONLines(t, buf.String())
+	if len(records) != 1 || records[0]["message"] != "JSON to a buffer" || records[0]["count"] != float64(3) {
+		t.Errorf("Expected one JSON record, got %v", records)
+	}
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatLogfmt), nil, &buf))
+
+	logger.Warn("Disk almost full", "path", "/var/log", "free", 0.05, "note", `say "hi"`,
+		"error", errors.New("no space"), slog.Group("req", "id", 7))
+
+	line := strings.TrimSpace(buf.String())
+	for _, want := range []string{
+		"level=WARN",
+		`message="Disk almost full"`,
+		"path=/var/log",
+		"free=0.05",
+		`note="say \"hi\""`,
+		`error="no space"`,
+		"req.id=7",
+		"service=FormatTest",
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected logfmt output to contain %s, got: %s", want, line)
+		}
+	}
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("Expected a single line per record, got: %q", buf.String())
+	}
+}
+
+func TestPrettyFormatsOverrideIsJsonOutput(t *testing.T) {
+	var text, pretty bytes.Buffer
+	textCfg := newFormatTestConfig(config.FormatPrettyText)
+	textCfg.Pretty.IsJsonOutput = true
+	slog.New(customhandler.NewHandler(textCfg, nil, &text)).Info("Pretty text", "key", "value")
+
+	slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatPrettyJSON), nil, &pretty)).Info("Pretty JSON", "key", "value")
+
+	if out := stripANSI(text.String()); !strings.Contains(out, "key=value") {
+		t.Errorf("Expected pretty-text output, got: %s", out)
+	}
+	if out := stripANSI(pretty.String()); !strings.Contains(out, `"key": "value"`) {
+		t.Errorf("Expected pretty-json output, got: %s", out)
+	}
+}
+
+func TestAutoFormatKeepsFileHeuristic(t *testing.T) {
+	var buf bytes.Buffer
+	slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatAuto), nil, &buf)).Info("Auto to a buffer")
+	if err := json.Unmarshal(buf.Bytes(), &map[string]any{}); err == nil {
+		t.Errorf("Expected pretty output for a buffer in auto mode, got: %s", buf.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create log file: %v", err)
+	}
+	defer file.Close()
+
+	slog.New(customhandler.NewHandler(newFormatTestConfig(""), nil, file)).Info("Auto to a file")
+	if records := readJSONLines(t, path); len(records) != 1 {
+		t.Errorf("Expected a JSON record in the file in auto mode, got %v", records)
+	}
+}
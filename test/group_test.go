@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+)
+
+func TestJSONNestedGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatJSON), nil, &buf))
+
+	logger.With("app", "shop").
+		WithGroup("request").With("id", "req-1").
+		Info("Handled", "status", 200, slog.Group("db", "queries", 3, slog.Group("pool", "idle", 2)))
+
+	records := decodeJSONLines(t, buf.String())
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	want := map[string]any{
+		"id":     "req-1",
+		"status": float64(200),
+		"db": map[string]any{
+			"queries": float64(3),
+			"pool":    map[string]any{"idle": float64(2)},
+		},
+	}
+	if !reflect.DeepEqual(records[0]["request"], want) {
+		t.Errorf("Expected the request group %v, got %v", want, records[0]["request"])
+	}
+	if records[0]["app"] != "shop" || records[0]["service"] != "FormatTest" {
+		t.Errorf("Expected ungrouped attributes at the top level, got %v", records[0])
+	}
+	for key := range records[0] {
+		if strings.Contains(key, ".") {
+			t.Errorf("Expected no dotted keys in JSON output, got %q", key)
+		}
+	}
+}
+
+func TestLogfmtUsesDottedGroupKeys(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatLogfmt), nil, &buf))
+
+	logger.WithGroup("request").Info("Handled", slog.Group("db", "queries", 3))
+
+	if !strings.Contains(buf.String(), "request.db.queries=3") {
+		t.Errorf("Expected a dotted key in logfmt output, got: %s", buf.String())
+	}
+}
+
+func TestConsoleGroupTree(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatPrettyText), nil, &buf))
+
+	logger.WithGroup("request").Info("Handled", "id", "req-1", slog.Group("db", "queries", 3))
+
+	output := stripANSI(buf.String())
+	for _, line := range []string{"\n  request:\n", "\n    id=req-1\n", "\n    db:\n", "\n      queries=3\n"} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected the console tree to contain %q, got: %s", line, output)
+		}
+	}
+	if strings.Contains(output, "[{") || strings.Contains(output, "request.") {
+		t.Errorf("Expected no slice dumps or dotted keys, got: %s", output)
+	}
+}
+
+func TestConsolePrettyJSONNestedGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatPrettyJSON), nil, &buf))
+
+	logger.WithGroup("request").Info("Handled", slog.Group("db", "queries", 3))
+
+	output := stripANSI(buf.String())
+	data := output[strings.Index(output, "Data:")+len("Data:"):]
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatalf("Expected a JSON Data block, got %q: %v", data, err)
+	}
+	want := map[string]any{"db": map[string]any{"queries": float64(3)}}
+	if !reflect.DeepEqual(decoded["request"], want) {
+		t.Errorf("Expected the request group %v, got %v", want, decoded["request"])
+	}
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+)
+
+func newHighlightTestLogger(w io.Writer, color string) *slog.Logger {
+	return newPrettyTestLogger(w, config.FormatPrettyJSON, config.PrettyConfig{Color: color})
+}
+
+var highlightTestArgs = []any{
+	"key2024", 42,
+	"negative", -1.5e-7,
+	"text", "true, false or null: 1",
+	"escaped", `say "hi" \ bye`,
+	"flag", false,
+	"missing", nil,
+	"list", []any{1, "two", true},
+}
+
+func TestJSONHighlighterTokens(t *testing.T) {
+	var buf bytes.Buffer
+	newHighlightTestLogger(&buf, config.ColorAlways).Info("Highlighted", highlightTestArgs...)
+
+	output := buf.String()
+	for _, want := range []string{
+		"\x1b[1;36m\"key2024\"\x1b[0m\x1b[33m:\x1b[0m \x1b[36m42\x1b[0m",
+		"\x1b[36m-1.5e-7\x1b[0m",
+		"\x1b[32m\"true, false or null: 1\"\x1b[0m",
+		"\x1b[32m\"say \\\"hi\\\" \\\\ bye\"\x1b[0m",
+		"\x1b[1;33mfalse\x1b[0m",
+		"\x1b[31mnull\x1b[0m",
+		"\x1b[1;35m[\x1b[0m",
+		"\x1b[32m\"two\"\x1b[0m",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got: %q", want, output)
+		}
+	}
+}
+
+func TestJSONHighlighterKeepsLayout(t *testing.T) {
+	var plain, colored bytes.Buffer
+	newHighlightTestLogger(&plain, config.ColorNever).Info("Highlighted", highlightTestArgs...)
+	newHighlightTestLogger(&colored, config.ColorAlways).Info("Highlighted", highlightTestArgs...)
+
+	if stripANSI(colored.String()) != plain.String() {
+		t.Errorf("Expected highlighting to keep the text, got:\n%s\nand:\n%s", colored.String(), plain.String())
+	}
+}
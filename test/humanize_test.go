@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aaffriya/logger/config"
+)
+
+var humanizeTestArgs = []any{
+	"elapsed", 1234 * time.Millisecond,
+	"slow", 205 * time.Second,
+	"size", 3565158,
+	"memory", 512,
+	"seen", time.Now().Add(-3*time.Minute - 5*time.Second),
+	"requests", 12345678,
+	"port", 50051,
+	"note", strings.Repeat("x", 130),
+	"ids", []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+}
+
+func TestHumanizedConsole(t *testing.T) {
+	var buf bytes.Buffer
+	newPrettyTestLogger(&buf, config.FormatPrettyText, config.PrettyConfig{Humanize: true, Color: config.ColorNever}).Info("Humanized", humanizeTestArgs...)
+
+	output := buf.String()
+	for _, want := range []string{
+		"elapsed=1.2s\n",
+		"slow=3m25s\n",
+		"size=3.4 MiB\n",
+		"memory=512 B\n",
+		"seen=3m ago\n",
+		"requests=12,345,678\n",
+		"port=50051\n",
+		"note=" + strings.Repeat("x", 100) + "… (+30 more)\n",
+		"ids=[1 2 3 4 5 6 7 8 9 10 …] (+2 more)\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got: %s", want, output)
+		}
+	}
+}
+
+func TestHumanizeKeepsRawValuesInJSON(t *testing.T) {
+	var buf bytes.Buffer
+	newPrettyTestLogger(&buf, config.FormatJSON, config.PrettyConfig{Humanize: true}).Info("Raw", humanizeTestArgs...)
+
+	output := buf.String()
+	for _, want := range []string{`"elapsed":"1.234s"`, `"size":3565158`, `"requests":12345678`, `"ids":[1,2,3,4,5,6,7,8,9,10,11,12]`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got: %s", want, output)
+		}
+	}
+	if strings.Contains(output, "more)") || strings.Contains(output, "ago") {
+		t.Errorf("Expected no humanized values in JSON, got: %s", output)
+	}
+}
+
+func TestHumanizeOff(t *testing.T) {
+	var buf bytes.Buffer
+	newPrettyTestLogger(&buf, config.FormatPrettyText, config.PrettyConfig{Color: config.ColorNever}).Info("Raw", "elapsed", 1234*time.Millisecond, "size", 3565158)
+
+	if !strings.Contains(buf.String(), "elapsed=1.234s\n") || !strings.Contains(buf.String(), "size=3565158\n") {
+		t.Errorf("Expected raw values without Humanize, got: %s", buf.String())
+	}
+}
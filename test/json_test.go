@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+)
+
+func TestJSONKeyOrder(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatJSON), nil, &buf))
+
+	logger.With("zeta", 1, "alpha", 2).Info("Ordered", "mid", 3, "beta", 4)
+
+	line := buf.String()
+	keys := []string{`"timestamp"`, `"level"`, `"message"`, `"service"`, `"version"`, `"zeta"`, `"alpha"`, `"mid"`, `"beta"`}
+	last := -1
+	for _, key := range keys {
+		i := strings.Index(line, key)
+		if i <= last {
+			t.Fatalf("Expected keys in the order %v, got: %s", keys, line)
+		}
+		last = i
+	}
+	if !strings.HasPrefix(line, `{"timestamp":`) {
+		t.Errorf("Expected the timestamp first, got: %s", line)
+	}
+}
+
+func TestJSONPreEncodedAttrsInGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatJSON), nil, &buf))
+
+	request := logger.WithGroup("request").With("id", "req-1").WithGroup("db")
+	request.Info("With attrs")
+	request.Info("Empty group")
+	logger.WithGroup("request").WithGroup("db").Info("Without attrs")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got: %s", buf.String())
+	}
+	decodeJSONLines(t, buf.String())
+	if !strings.Contains(lines[0], `"request":{"id":"req-1"}}`) {
+		t.Errorf("Expected the prefix group closed without an empty db group, got: %s", lines[0])
+	}
+	if strings.Contains(lines[2], `"request"`) {
+		t.Errorf("Expected groups without attributes to be dropped, got: %s", lines[2])
+	}
+
+	buf.Reset()
+	request.Info("Nested", "queries", 3)
+	if !strings.Contains(buf.String(), `"request":{"id":"req-1","db":{"queries":3}}}`) {
+		t.Errorf("Expected record attributes in the open groups, got: %s", buf.String())
+	}
+}
+
+func TestJSONStringEscaping(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatJSON), nil, &buf))
+
+	value := "quote\" slash\\ newline\n tab\t ctrl\x01 html<&> sep\u2028 bad\xff"
+	logger.Info("Escaped", "value", value)
+
+	records := decodeJSONLines(t, buf.String())
+	want := strings.Replace(value, "\xff", "\ufffd", 1)
+	if records[0]["value"] != want {
+		t.Errorf("Expected %q to round trip, got %q", want, records[0]["value"])
+	}
+	if !strings.Contains(buf.String(), "<&>") || !strings.Contains(buf.String(), `\u2028`) {
+		t.Errorf("Expected HTML kept and U+2028 escaped, got: %s", buf.String())
+	}
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	rootlogger "github.com/aaffriya/logger"
+	"github.com/aaffriya/logger/config"
+)
+
+func TestPrettyJSONWriterSplitsLines(t *testing.T) {
+	var buf bytes.Buffer
+	w := rootlogger.NewPrettyJSONWriter(&buf, &config.PrettyConfig{Color: config.ColorNever})
+
+	input := `{"time":"2024-01-02T03:04:05Z","level":"WARN","msg":"first","n":1}` + "\n" +
+		`{"level":"ERROR","msg":"second","req":{"id":"r-1","ms":12.5}}` + "\n" +
+		"not json\n" +
+		`{"level":"DEBUG","msg":"partial"}`
+
+	// Write byte by byte, then the rest in one call
+	for i := range 30 {
+		w.Write([]byte{input[i]})
+	}
+	w.Write([]byte(input[30:]))
+
+	want := "[WARN] first\n  n=1\n" +
+		"[ERROR] second\n  req:\n    id=r-1\n    ms=12.5\n" +
+		"not json\n"
+	if buf.String() != want {
+		t.Fatalf("Expected %q, got %q", want, buf.String())
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want += "[DEBUG] partial\n"; buf.String() != want {
+		t.Errorf("Expected the partial line to be rendered on Close, got %q", buf.String())
+	}
+}
+
+func TestPrettyJSONWriterMatchesHandler(t *testing.T) {
+	pretty := config.PrettyConfig{IncludeTimestamp: true, Color: config.ColorAlways}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	args := []any{"user_id", "u-1", "status", 503, slog.Group("db", "rows", 3), "ok", true}
+
+	var direct bytes.Buffer
+	handler := newPrettyTestLogger(&direct, config.FormatPrettyText, pretty).Handler()
+
+	var rendered bytes.Buffer
+	w := rootlogger.NewPrettyJSONWriter(&rendered, &pretty)
+	jsonHandler := slog.NewJSONHandler(w, nil)
+
+	for _, h := range []slog.Handler{handler, jsonHandler} {
+		r := slog.NewRecord(now, slog.LevelError, "Request failed", 0)
+		r.Add(args...)
+		h.Handle(t.Context(), r)
+	}
+
+	if rendered.String() != direct.String() {
+		t.Errorf("Expected the same layout as the handler:\n%q\ngot:\n%q", direct.String(), rendered.String())
+	}
+}
+
+func TestPrettyJSONWriterEscapesPassthrough(t *testing.T) {
+	var buf bytes.Buffer
+	w := rootlogger.NewPrettyJSONWriter(&buf, &config.PrettyConfig{Color: config.ColorNever})
+	w.Write([]byte("plain \x1b[2J text\n"))
+
+	if strings.Contains(buf.String(), "\x1b") {
+		t.Errorf("Expected escape sequences in plain lines to be escaped, got %q", buf.String())
+	}
+}
@@ -0,0 +1,204 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+)
+
+func TestCallerLevelTakesPrecedence(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := newFormatTestConfig(config.FormatJSON)
+	cfg.Level = "debug"
+	opts := &slog.HandlerOptions{Level: slog.LevelWarn}
+
+	logger := slog.New(customhandler.NewHandler(cfg, opts, &buf))
+	logger.Info("Below the caller level")
+	logger.Warn("At the caller level")
+
+	records := decodeJSONLines(t, buf.String())
+	if len(records) != 1 || records[0]["message"] != "At the caller level" {
+		t.Errorf("Expected only the warning, got %v", records)
+	}
+	if opts.Level != slog.LevelWarn {
+		t.Errorf("Expected the caller's options to be left alone, got level %v", opts.Level)
+	}
+}
+
+func TestConfigLevelDoesNotModifyOptions(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := newFormatTestConfig(config.FormatJSON)
+	cfg.Level = "error"
+	opts := &slog.HandlerOptions{}
+
+	slog.New(customhandler.NewHandler(cfg, opts, &buf)).Warn("Below the config level")
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected config.Level to apply, got: %s", buf.String())
+	}
+	if opts.Level != nil {
+		t.Errorf("Expected the caller's options to be left alone, got level %v", opts.Level)
+	}
+}
+
+func TestReplaceAttrRenamesAndDrops(t *testing.T) {
+	var seen [][]string
+	opts := &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch a.Key {
+			case slog.TimeKey, "password", "version":
+				return slog.Attr{}
+			case "user":
+				a.Key = "user_name"
+			case "table":
+				seen = append(seen, groups)
+			}
+			return a
+		},
+	}
+
+	var jsonBuf, textBuf bytes.Buffer
+	handler := customhandler.NewSinkHandler(newFormatTestConfig(""), opts,
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatJSON}, Writer: &jsonBuf},
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatPrettyText}, Writer: &textBuf},
+	)
+	logger := slog.New(handler)
+
+	logger.Info("Login", "user", "alice", "password", "secret")
+	logger.WithGroup("req").Info("Query", slog.Group("db", "table", "users", "password", "secret"))
+
+	records := decodeJSONLines(t, jsonBuf.String())
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0]["user_name"] != "alice" || records[0]["user"] != nil {
+		t.Errorf("Expected user to be renamed to user_name, got %v", records[0])
+	}
+	for _, record := range records {
+		if _, ok := record["timestamp"]; ok {
+			t.Errorf("Expected the time to be dropped, got %v", record)
+		}
+		if _, ok := record["version"]; ok {
+			t.Errorf("Expected the version field to be dropped, got %v", record)
+		}
+	}
+	if strings.Contains(jsonBuf.String(), "secret") || strings.Contains(textBuf.String(), "secret") {
+		t.Errorf("Expected passwords to be dropped in every sink, got: %s%s", jsonBuf.String(), textBuf.String())
+	}
+	if !strings.Contains(stripANSI(textBuf.String()), "user_name=alice") {
+		t.Errorf("Expected the rename in the console sink, got: %s", textBuf.String())
+	}
+
+	// ReplaceAttr runs once per record, not once per sink
+	if len(seen) != 1 || !slices.Equal(seen[0], []string{"req", "db"}) {
+		t.Errorf("Expected table to be replaced once within [req db], got %v", seen)
+	}
+}
+
+func TestReplaceAttrBuiltins(t *testing.T) {
+	var buf bytes.Buffer
+	opts := &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch a.Key {
+			case slog.MessageKey:
+				return slog.String("msg", strings.ToUpper(a.Value.String()))
+			case slog.LevelKey:
+				return slog.Any(slog.LevelKey, slog.LevelError)
+			}
+			return a
+		},
+	}
+
+	slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatJSON), opts, &buf)).Info("quiet message")
+
+	records := decodeJSONLines(t, buf.String())
+	if len(records) != 1 || records[0]["message"] != "QUIET MESSAGE" || records[0]["level"] != "ERROR" {
+		t.Errorf("Expected the replaced message and level, got %v", records)
+	}
+}
+
+func TestReplaceAttrStringLevel(t *testing.T) {
+	opts := &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && a.Value.Any() == slog.LevelWarn {
+				return slog.String(slog.LevelKey, "WARNING")
+			}
+			return a
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{config.FormatJSON, `"level":"WARNING"`},
+		{config.FormatLogfmt, "level=WARNING"},
+		{config.FormatPrettyText, "[WARNING] Disk almost full"},
+		{config.FormatPrettyJSON, "[WARNING] Disk almost full"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			cfg := newFormatTestConfig(tt.format)
+			cfg.Pretty.Color = config.ColorNever
+			slog.New(customhandler.NewHandler(cfg, opts, &buf)).Warn("Disk almost full", "free", "5%")
+
+			output := buf.String()
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("Expected %q in the output, got: %s", tt.want, output)
+			}
+			if strings.Count(output, "WARN") != 1 {
+				t.Errorf("Expected the replaced level once and no level attribute, got: %s", output)
+			}
+		})
+	}
+}
+
+func TestReplaceAttrBuiltinsOfOtherKinds(t *testing.T) {
+	var buf bytes.Buffer
+	opts := &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch a.Key {
+			case slog.TimeKey:
+				return slog.Int64(slog.TimeKey, 1700000000)
+			case slog.MessageKey:
+				return slog.Int(slog.MessageKey, 42)
+			}
+			return a
+		},
+	}
+
+	slog.New(customhandler.NewHandler(newFormatTestConfig(config.FormatJSON), opts, &buf)).Info("replaced")
+
+	records := decodeJSONLines(t, buf.String())
+	if len(records) != 1 || records[0]["timestamp"] != float64(1700000000) || records[0]["message"] != float64(42) {
+		t.Errorf("Expected the replaced timestamp and message fields, got %v", records)
+	}
+	if _, ok := records[0]["time"]; ok || records[0]["msg"] != nil {
+		t.Errorf("Expected no duplicate attributes, got %v", records[0])
+	}
+}
+
+func TestAddSource(t *testing.T) {
+	var jsonBuf, textBuf bytes.Buffer
+	handler := customhandler.NewSinkHandler(newFormatTestConfig(""), &slog.HandlerOptions{AddSource: true},
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatJSON}, Writer: &jsonBuf},
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatPrettyText}, Writer: &textBuf},
+	)
+
+	slog.New(handler).Info("With source")
+
+	records := decodeJSONLines(t, jsonBuf.String())
+	source, _ := records[0]["source"].(map[string]any)
+	if file, _ := source["file"].(string); !strings.HasSuffix(file, "options_test.go") {
+		t.Errorf("Expected the source to point at this file, got %v", records[0]["source"])
+	}
+	if !strings.Contains(stripANSI(textBuf.String()), "options_test.go:") {
+		t.Errorf("Expected the console to show file:line, got: %s", textBuf.String())
+	}
+}
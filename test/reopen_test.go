@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+	filehandler "github.com/aaffriya/logger/internal/handler/file"
+)
+
+type reopenableHandler interface {
+	slog.Handler
+	Reopen() error
+}
+
+func TestReopenAfterExternalRename(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	defer file.Close()
+
+	handler := customhandler.NewHandler(newRotationTestConfig(path, config.RotationConfig{}), nil, file).(reopenableHandler)
+	logger := slog.New(handler)
+
+	logger.Info("Before logrotate")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("Failed to rename log file: %v", err)
+	}
+	logger.Info("Written to the orphaned inode")
+
+	if err := handler.Reopen(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	logger.With("derived", true).Info("After reopen")
+
+	if got := len(readJSONLines(t, path+".1")); got != 2 {
+		t.Errorf("Expected 2 records in the rotated file, got %d", got)
+	}
+	records := readJSONLines(t, path)
+	if len(records) != 1 || records[0]["message"] != "After reopen" {
+		t.Errorf("Expected only the record after reopen in the new file, got %v", records)
+	}
+}
+
+func TestReopenPrettyFormats(t *testing.T) {
+	openers := map[string]func(path string) (io.WriteCloser, error){
+		"file": func(path string) (io.WriteCloser, error) {
+			return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		},
+		"rotating": func(path string) (io.WriteCloser, error) {
+			return filehandler.OpenRotatingFile(config.FileConfig{Path: path})
+		},
+	}
+
+	for name, open := range openers {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			w, err := open(path)
+			if err != nil {
+				t.Fatalf("Failed to open log file: %v", err)
+			}
+			defer w.Close()
+
+			cfg := newRotationTestConfig(path, config.RotationConfig{})
+			cfg.Format = config.FormatPrettyText
+			cfg.Pretty.Color = config.ColorNever
+			handler := customhandler.NewHandler(cfg, nil, w).(reopenableHandler)
+			logger := slog.New(handler)
+
+			logger.Info("Before logrotate")
+			if err := os.Rename(path, path+".1"); err != nil {
+				t.Fatalf("Failed to rename log file: %v", err)
+			}
+			if err := handler.Reopen(); err != nil {
+				t.Fatalf("Failed to reopen: %v", err)
+			}
+			logger.Info("After reopen")
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Expected the log file to be recreated: %v", err)
+			}
+			if string(data) != "[INFO] After reopen\n" {
+				t.Errorf("Expected only the record after reopen in the new file, got %q", data)
+			}
+		})
+	}
+}
+
+func TestReopenLosesNoRecordsUnderLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	file, err := filehandler.OpenRotatingFile(config.FileConfig{Path: path})
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	defer file.Close()
+
+	handler := customhandler.NewHandler(newRotationTestConfig(path, config.RotationConfig{}), nil, file).(reopenableHandler)
+	logger := slog.New(handler)
+
+	const writers, perWriter = 8, 200
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				logger.Info("Concurrent message", "writer", w, "index", i)
+			}
+		}(w)
+	}
+
+	for i := 1; i <= 5; i++ {
+		os.Rename(path, fmt.Sprintf("%s.%d", path, i))
+		if err := handler.Reopen(); err != nil {
+			t.Fatalf("Failed to reopen: %v", err)
+		}
+	}
+	wg.Wait()
+
+	matches, _ := filepath.Glob(path + "*")
+	total := 0
+	for _, name := range matches {
+		total += len(readJSONLines(t, name))
+	}
+	if total != writers*perWriter {
+		t.Errorf("Expected %d records across %d files, got %d", writers*perWriter, len(matches), total)
+	}
+}
@@ -0,0 +1,364 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aaffriya/logger/config"
+	"github.com/aaffriya/logger/internal/diag"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+	filehandler "github.com/aaffriya/logger/internal/handler/file"
+)
+
+func newRotationTestConfig(path string, rotation config.RotationConfig) *config.LoggerConfig {
+	return &config.LoggerConfig{
+		DefaultFields: config.DefaultFieldInfo{
+			Version: "v1.0.0",
+			Service: "RotationTest",
+		},
+		Stack: config.StackConfig{
+			Enabled: false,
+		},
+		File: config.FileConfig{
+			Path:     path,
+			Rotation: rotation,
+		},
+	}
+}
+
+// readJSONLines decodes every line of a plain or gzipped log file
+func readJSONLines(t *testing.T, name string) []map[string]any {
+	t.Helper()
+
+	file, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", name, err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("Failed to open gzip %s: %v", name, err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	var records []map[string]any
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid JSON line in %s: %q: %v", name, scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSizeBasedRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotation := config.RotationConfig{MaxSize: 1024, MaxBackups: 3}
+
+	file, err := filehandler.OpenRotatingFile(config.FileConfig{Path: path, Rotation: rotation})
+	if err != nil {
+		t.Fatalf("Failed to open rotating file: %v", err)
+	}
+
+	logger := slog.New(customhandler.NewHandler(newRotationTestConfig(path, rotation), nil, file))
+	for i := 0; i < 100; i++ {
+		logger.Info("Rotation test message", "index", i)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close rotating file: %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(matches) != rotation.MaxBackups {
+		t.Errorf("Expected %d backups, got %d: %v", rotation.MaxBackups, len(matches), matches)
+	}
+
+	for _, name := range append(matches, path) {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", name, err)
+		}
+		if info.Size() > rotation.MaxSize {
+			t.Errorf("Expected %s to be at most %d bytes, got %d", name, rotation.MaxSize, info.Size())
+		}
+		if len(readJSONLines(t, name)) == 0 {
+			t.Errorf("Expected %s to contain records", name)
+		}
+	}
+
+	// The newest record always lands in the active file
+	records := readJSONLines(t, path)
+	if last := records[len(records)-1]; last["index"] != float64(99) {
+		t.Errorf("Expected last record index 99, got %v", last["index"])
+	}
+}
+
+func TestSizeBasedRotationCompress(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotation := config.RotationConfig{MaxSize: 512, Compress: true}
+
+	file, err := filehandler.OpenRotatingFile(config.FileConfig{Path: path, Rotation: rotation})
+	if err != nil {
+		t.Fatalf("Failed to open rotating file: %v", err)
+	}
+
+	logger := slog.New(customhandler.NewHandler(newRotationTestConfig(path, rotation), nil, file))
+	for i := 0; i < 20; i++ {
+		logger.Info("Compression test message", "index", i)
+	}
+	file.Close()
+
+	plain, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(plain) != 0 {
+		t.Errorf("Expected all backups to be compressed, found %v", plain)
+	}
+
+	compressed, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	if len(compressed) == 0 {
+		t.Fatal("Expected compressed backups")
+	}
+
+	total := len(readJSONLines(t, path))
+	for _, name := range compressed {
+		total += len(readJSONLines(t, name))
+	}
+	if total != 20 {
+		t.Errorf("Expected 20 records across all files, got %d", total)
+	}
+}
+
+func TestTimeBasedRotationUsesRecordTime(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotation := config.RotationConfig{Pattern: "app-%Y-%m-%d.log"}
+
+	file, err := filehandler.OpenRotatingFile(config.FileConfig{Path: path, Rotation: rotation})
+	if err != nil {
+		t.Fatalf("Failed to open rotating file: %v", err)
+	}
+
+	handler := customhandler.NewHandler(newRotationTestConfig(path, rotation), nil, file)
+	day := time.Date(2024, 3, 10, 22, 0, 0, 0, time.UTC)
+	times := []time.Time{
+		day,
+		day.Add(3 * time.Hour),    // next day
+		day.Add(30 * time.Minute), // late record stays in the active file
+		day.Add(26 * time.Hour),   // next day again
+		day.Add(26*time.Hour + 1), // same day
+	}
+	for i, ts := range times {
+		record := slog.NewRecord(ts, slog.LevelInfo, "Replayed message", 0)
+		record.AddAttrs(slog.Int("index", i))
+		if err := handler.Handle(context.Background(), record); err != nil {
+			t.Fatalf("Failed to handle record: %v", err)
+		}
+	}
+	file.Close()
+
+	expected := map[string]int{
+		"app-2024-03-10.log": 1,
+		"app-2024-03-11.log": 2,
+		"app-2024-03-12.log": 2,
+	}
+	for name, count := range expected {
+		if got := len(readJSONLines(t, filepath.Join(dir, name))); got != count {
+			t.Errorf("Expected %d records in %s, got %d", count, name, got)
+		}
+	}
+
+	target, err := os.Readlink(path)
+	if err != nil {
+		t.Fatalf("Expected %s to be a symlink: %v", path, err)
+	}
+	if target != "app-2024-03-12.log" {
+		t.Errorf("Expected symlink to point at app-2024-03-12.log, got %s", target)
+	}
+}
+
+func TestPrettyRotationUsesRecordTime(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotation := config.RotationConfig{Pattern: "app-%Y-%m-%d.log"}
+
+	file, err := filehandler.OpenRotatingFile(config.FileConfig{Path: path, Rotation: rotation})
+	if err != nil {
+		t.Fatalf("Failed to open rotating file: %v", err)
+	}
+
+	cfg := newRotationTestConfig(path, rotation)
+	cfg.Format = config.FormatPrettyText
+	cfg.Pretty.Color = config.ColorNever
+	handler := customhandler.NewHandler(cfg, nil, file)
+	day := time.Date(2024, 3, 10, 22, 0, 0, 0, time.UTC)
+	for _, ts := range []time.Time{day, day.Add(3 * time.Hour)} {
+		if err := handler.Handle(context.Background(), slog.NewRecord(ts, slog.LevelInfo, "Replayed message", 0)); err != nil {
+			t.Fatalf("Failed to handle record: %v", err)
+		}
+	}
+	file.Close()
+
+	for _, name := range []string{"app-2024-03-10.log", "app-2024-03-11.log"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected a file for the day of each record: %v", err)
+		}
+		if got := strings.Count(string(data), "Replayed message"); got != 1 {
+			t.Errorf("Expected 1 record in %s, got %d", name, got)
+		}
+	}
+}
+
+func TestPatternRotationMovesExistingFileAside(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("earlier run\n"), 0666); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+	rotation := config.RotationConfig{Pattern: "app-%Y-%m-%d.log"}
+
+	file, err := filehandler.OpenRotatingFile(config.FileConfig{Path: path, Rotation: rotation})
+	if err != nil {
+		t.Fatalf("Failed to open rotating file: %v", err)
+	}
+	handler := customhandler.NewHandler(newRotationTestConfig(path, rotation), nil, file)
+	record := slog.NewRecord(time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), slog.LevelInfo, "New message", 0)
+	if err := handler.Handle(context.Background(), record); err != nil {
+		t.Fatalf("Failed to handle record: %v", err)
+	}
+	file.Close()
+
+	if target, err := os.Readlink(path); err != nil || target != "app-2024-03-10.log" {
+		t.Errorf("Expected %s to link to the active file, got %q, %v", path, target, err)
+	}
+	moved, _ := filepath.Glob(filepath.Join(dir, "app-*T*.log"))
+	if len(moved) != 1 {
+		t.Fatalf("Expected the earlier log file to be moved aside, got %v", moved)
+	}
+	if data, _ := os.ReadFile(moved[0]); string(data) != "earlier run\n" {
+		t.Errorf("Expected the earlier log file to be kept, got %q", data)
+	}
+}
+
+func TestHourlyRotationWithRetention(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotation := config.RotationConfig{Interval: "hourly", MaxBackups: 2, Compress: true}
+
+	file, err := filehandler.OpenRotatingFile(config.FileConfig{Path: path, Rotation: rotation})
+	if err != nil {
+		t.Fatalf("Failed to open rotating file: %v", err)
+	}
+
+	handler := customhandler.NewHandler(newRotationTestConfig(path, rotation), nil, file)
+	start := time.Date(2024, 3, 10, 8, 15, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		record := slog.NewRecord(start.Add(time.Duration(i)*time.Hour), slog.LevelInfo, "Hourly message", 0)
+		if err := handler.Handle(context.Background(), record); err != nil {
+			t.Fatalf("Failed to handle record: %v", err)
+		}
+	}
+	file.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "app-2024-03-10-12.log")); err != nil {
+		t.Errorf("Expected active hourly file: %v", err)
+	}
+
+	compressed, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	if len(compressed) != rotation.MaxBackups {
+		t.Errorf("Expected %d compressed backups, got %v", rotation.MaxBackups, compressed)
+	}
+	for _, name := range compressed {
+		if !strings.Contains(name, "2024-03-10-10") && !strings.Contains(name, "2024-03-10-11") {
+			t.Errorf("Expected only the newest hours to be kept, found %s", name)
+		}
+	}
+}
+
+func TestRetentionPolicyReportsRemovals(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	now := time.Now()
+	backups := []struct {
+		name string
+		age  time.Duration
+		size int
+	}{
+		{"app-2024-03-01T10-00-00.000.log", 1 * time.Hour, 100},
+		{"app-2024-03-01T09-00-00.000.log.gz", 2 * time.Hour, 300},
+		{"app-2024-03-01T08-00-00.000.log", 3 * time.Hour, 300},
+		{"app-2024-02-01T08-00-00.000.log", 30 * 24 * time.Hour, 10},
+		{"unrelated.log", 30 * 24 * time.Hour, 10},
+	}
+	for _, b := range backups {
+		name := filepath.Join(dir, b.name)
+		if err := os.WriteFile(name, []byte(strings.Repeat("x", b.size)), 0666); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		modTime := now.Add(-b.age)
+		os.Chtimes(name, modTime, modTime)
+	}
+
+	var diagnostics bytes.Buffer
+	diag.SetLogger(slog.New(slog.NewJSONHandler(&diagnostics, nil)))
+	defer diag.SetLogger(nil)
+
+	file, err := filehandler.OpenRotatingFile(config.FileConfig{
+		Path: path,
+		Retention: config.RetentionConfig{
+			MaxAge:       7 * 24 * time.Hour,
+			MaxTotalSize: 500,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open rotating file: %v", err)
+	}
+	file.Close()
+
+	for _, b := range backups {
+		_, err := os.Stat(filepath.Join(dir, b.name))
+		kept := b.name == "app-2024-03-01T10-00-00.000.log" ||
+			b.name == "app-2024-03-01T09-00-00.000.log.gz" ||
+			b.name == "unrelated.log"
+		if kept && err != nil {
+			t.Errorf("Expected %s to be kept: %v", b.name, err)
+		}
+		if !kept && err == nil {
+			t.Errorf("Expected %s to be removed", b.name)
+		}
+	}
+
+	reasons := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(diagnostics.String()), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid diagnostics line %q: %v", line, err)
+		}
+		reasons[filepath.Base(event["file"].(string))] = event["reason"].(string)
+	}
+	if reasons["app-2024-03-01T08-00-00.000.log"] != "max_total_size" {
+		t.Errorf("Expected max_total_size removal to be reported, got %v", reasons)
+	}
+	if reasons["app-2024-02-01T08-00-00.000.log"] != "max_age" {
+		t.Errorf("Expected max_age removal to be reported, got %v", reasons)
+	}
+}
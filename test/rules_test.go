@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+	"github.com/aaffriya/logger/pkg/pretty"
+)
+
+func logRulesTest(rules *pretty.Registry, args ...any) string {
+	var buf bytes.Buffer
+	newPrettyTestLogger(&buf, config.FormatPrettyText, config.PrettyConfig{Color: config.ColorAlways, Rules: rules}).Info("Rules", args...)
+	return buf.String()
+}
+
+func TestValueRulesFormatAndColor(t *testing.T) {
+	rules := pretty.NewRegistry()
+	rules.Register(pretty.Rule{KeyGlob: "*_ms", Type: pretty.TypeNumber, Color: "bold #ff8700", Format: func(f pretty.Field) pretty.Result {
+		return pretty.Result{Display: f.Text + "ms"}
+	}})
+
+	output := logRulesTest(rules, "db_ms", 42, "code", 200)
+	for _, want := range []string{"\x1b[1;38;2;255;135;0m42ms", "\x1b[1;32m200"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got: %q", want, output)
+		}
+	}
+}
+
+func TestValueRulesDefaultsDisabled(t *testing.T) {
+	rules := pretty.NewRegistry()
+	rules.DisableDefaults()
+
+	output := logRulesTest(rules, "code", 503, "result", "failed")
+	for _, want := range []string{"\x1b[36m503", "\x1b[97mfailed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got: %q", want, output)
+		}
+	}
+}
+
+func TestValueRulesWholeWords(t *testing.T) {
+	output := logRulesTest(nil, "token", "xyzok123", "result", "ok")
+	if !strings.Contains(output, "\x1b[97mxyzok123") {
+		t.Errorf("Expected a token containing ok to keep the string color, got: %q", output)
+	}
+	if !strings.Contains(output, "\x1b[1;32mok") {
+		t.Errorf("Expected ok to be colored as good, got: %q", output)
+	}
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+)
+
+func logSanitizeTest(format string, pretty config.PrettyConfig, msg string, args ...any) string {
+	var buf bytes.Buffer
+	pretty.Color = config.ColorNever
+	newPrettyTestLogger(&buf, format, pretty).Info(msg, args...)
+	return buf.String()
+}
+
+// forgedLine reports whether a line of output starts like a record
+func forgedLine(output string) bool {
+	for _, line := range strings.Split(output, "\n")[1:] {
+		if strings.HasPrefix(line, "[") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSanitizeForgedLines(t *testing.T) {
+	forged := "ok\n[ERROR] forged"
+	for name, pretty := range map[string]config.PrettyConfig{
+		"text":    {},
+		"compact": {Compact: true},
+		"columns": {Columns: true},
+	} {
+		output := logSanitizeTest(config.FormatPrettyText, pretty, forged, "user_id", forged, "input", forged, forged, "value")
+		if forgedLine(output) {
+			t.Errorf("%s: expected no forged record line, got:\n%s", name, output)
+		}
+		if !strings.Contains(output, "    [ERROR] forged") && !strings.Contains(output, `\n[ERROR] forged`) {
+			t.Errorf("%s: expected the forged line to be indented or escaped, got:\n%s", name, output)
+		}
+	}
+}
+
+func TestSanitizeContinuationLines(t *testing.T) {
+	output := logSanitizeTest(config.FormatPrettyText, config.PrettyConfig{}, "first\nsecond", "input", "a\nb")
+
+	want := "[INFO] first\n    second\n  input=a\n        b\n"
+	if output != want {
+		t.Errorf("Expected %q, got %q", want, output)
+	}
+}
+
+func TestSanitizeEscapeSequences(t *testing.T) {
+	for _, format := range []string{config.FormatPrettyText, config.FormatPrettyJSON} {
+		output := logSanitizeTest(format, config.PrettyConfig{Compact: format == config.FormatPrettyText},
+			"bell\a \x1b[2J", "input", "\x1b]0;title\x07", "bidi", "abc\u202edef", "key\x1b[31m", "v", "bad", "\xff")
+
+		if strings.ContainsAny(output, "\x1b\a\u202e\xff") {
+			t.Errorf("%s: expected control characters to be escaped, got %q", format, output)
+		}
+	}
+
+	output := logSanitizeTest(config.FormatPrettyText, config.PrettyConfig{}, "bell\a \x1b[2J", "bidi", "abc\u202edef", "tag", "x\U000e0041", "bad", "\xff", "emoji", "👩\u200d💻 café")
+	for _, want := range []string{`bell\x07 \x1b[2J`, `tag=x\U000e0041`, `bidi=abc\u202edef`, `bad=\xff`, "emoji=👩\u200d💻 café"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got %q", want, output)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+//go:build unix || windows
+
+package logger
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	rootlogger "github.com/aaffriya/logger"
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+	filehandler "github.com/aaffriya/logger/internal/handler/file"
+)
+
+func TestReopenOnSIGHUP(t *testing.T) {
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Skipf("Cannot find own process: %v", err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	file, err := filehandler.OpenRotatingFile(config.FileConfig{Path: path})
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	defer file.Close()
+
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	slog.SetDefault(slog.New(customhandler.NewHandler(newRotationTestConfig(path, config.RotationConfig{}), nil, file)))
+
+	stop := rootlogger.ReopenOnSIGHUP()
+	defer stop()
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("Failed to rename log file: %v", err)
+	}
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		t.Skipf("Cannot send SIGHUP on this platform: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the log file to be recreated after SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	slog.Info("After SIGHUP")
+	if got := len(readJSONLines(t, path)); got != 1 {
+		t.Errorf("Expected 1 record in the reopened file, got %d", got)
+	}
+}
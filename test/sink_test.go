@@ -0,0 +1,222 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	rootlogger "github.com/aaffriya/logger"
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+	ctxmeta "github.com/aaffriya/logger/pkg/context"
+)
+
+func newSinkTestConfig() *config.LoggerConfig {
+	return &config.LoggerConfig{
+		DefaultFields: config.DefaultFieldInfo{
+			Version: "v1.0.0",
+			Service: "SinkTest",
+		},
+		Level: "debug",
+	}
+}
+
+// decodeJSONLines decodes every line of JSON output written to a buffer
+func decodeJSONLines(t *testing.T, output string) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSinksFanOutByLevel(t *testing.T) {
+	var console, file, alerts bytes.Buffer
+	handler := customhandler.NewSinkHandler(newSinkTestConfig(), nil,
+		customhandler.Sink{Config: config.SinkConfig{Name: "console", Level: "debug", Format: config.FormatPretty}, Writer: &console},
+		customhandler.Sink{Config: config.SinkConfig{Name: "file", Level: "info", Format: config.FormatJSON}, Writer: &file},
+		customhandler.Sink{Config: config.SinkConfig{Name: "alerts", Level: "error", Format: config.FormatJSON}, Writer: &alerts},
+	)
+	logger := slog.New(handler)
+
+	logger.Debug("Debug message")
+	logger.Info("Info message")
+	logger.Error("Error message")
+
+	consoleOutput := stripANSI(console.String())
+	for _, message := range []string{"Debug message", "Info message", "Error message"} {
+		if !strings.Contains(consoleOutput, message) {
+			t.Errorf("Expected console output to contain %q, got: %s", message, consoleOutput)
+		}
+	}
+
+	fileRecords := decodeJSONLines(t, file.String())
+	if len(fileRecords) != 2 || fileRecords[0]["message"] != "Info message" || fileRecords[1]["message"] != "Error message" {
+		t.Errorf("Expected the info and error records in the file sink, got %v", fileRecords)
+	}
+
+	alertRecords := decodeJSONLines(t, alerts.String())
+	if len(alertRecords) != 1 || alertRecords[0]["message"] != "Error message" {
+		t.Errorf("Expected only the error record in the alerts sink, got %v", alertRecords)
+	}
+}
+
+func TestSinksShareEnrichment(t *testing.T) {
+	var console, file bytes.Buffer
+	handler := customhandler.NewSinkHandler(newSinkTestConfig(), nil,
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatPretty}, Writer: &console},
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatJSON}, Writer: &file},
+	)
+	logger := slog.New(handler).With("request", "req-1")
+
+	ctx := ctxmeta.WithTraceID(context.Background(), "trace-sink-1")
+	logger.InfoContext(ctx, "Shared record")
+
+	for _, want := range []string{"trace-sink-1", "req-1"} {
+		if !strings.Contains(console.String(), want) {
+			t.Errorf("Expected console output to contain %q, got: %s", want, console.String())
+		}
+	}
+
+	records := decodeJSONLines(t, file.String())
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record in the file sink, got %d", len(records))
+	}
+	if records[0]["trace_id"] != "trace-sink-1" || records[0]["request"] != "req-1" || records[0]["service"] != "SinkTest" {
+		t.Errorf("Expected the file record to carry the shared fields, got %v", records[0])
+	}
+}
+
+func TestSinksStackPerSink(t *testing.T) {
+	var short, deep, none bytes.Buffer
+	stack := func(skip, depth int) *config.StackConfig {
+		return &config.StackConfig{Enabled: true, Skip: skip, Depth: config.StackDepths{Error: depth}}
+	}
+	handler := customhandler.NewSinkHandler(newSinkTestConfig(), nil,
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatJSON, Stack: stack(4, 1)}, Writer: &short},
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatJSON, Stack: stack(3, 3)}, Writer: &deep},
+		customhandler.Sink{Config: config.SinkConfig{Format: config.FormatJSON}, Writer: &none},
+	)
+
+	slog.New(handler).Error("Error with stack trace")
+
+	shortTrace, _ := decodeJSONLines(t, short.String())[0]["trace"].([]any)
+	deepTrace, _ := decodeJSONLines(t, deep.String())[0]["trace"].([]any)
+	if len(shortTrace) != 1 || len(deepTrace) != 3 {
+		t.Fatalf("Expected traces of 1 and 3 frames, got %v and %v", shortTrace, deepTrace)
+	}
+	if shortTrace[0] != deepTrace[1] {
+		t.Errorf("Expected both sinks to slice the same trace, got %v and %v", shortTrace, deepTrace)
+	}
+	if _, ok := decodeJSONLines(t, none.String())[0]["trace"]; ok {
+		t.Error("Expected no trace in the sink with stack traces disabled")
+	}
+}
+
+func TestSetupMultiLogger(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	dir := t.TempDir()
+	cfg := newSinkTestConfig()
+	cfg.Sinks = []config.SinkConfig{
+		{Name: "app", Output: filepath.Join(dir, "app.log"), Level: "info"},
+		{Name: "errors", Output: filepath.Join(dir, "errors.log"), Level: "error"},
+	}
+
+	closer, err := rootlogger.SetupMultiLogger(cfg, nil)
+	if err != nil {
+		t.Fatalf("Failed to set up sinks: %v", err)
+	}
+
+	slog.Debug("Debug message")
+	slog.Info("Info message")
+	slog.Error("Error message")
+
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	if got := len(readJSONLines(t, filepath.Join(dir, "app.log"))); got != 2 {
+		t.Errorf("Expected 2 records in app.log, got %d", got)
+	}
+	if got := len(readJSONLines(t, filepath.Join(dir, "errors.log"))); got != 1 {
+		t.Errorf("Expected 1 record in errors.log, got %d", got)
+	}
+}
+
+func TestSetupMultiLoggerRejectsMissingOutput(t *testing.T) {
+	cfg := newSinkTestConfig()
+	cfg.Sinks = []config.SinkConfig{{Name: "broken"}}
+
+	if _, err := rootlogger.SetupMultiLogger(cfg, nil); err == nil {
+		t.Error("Expected an error for a sink without output")
+	}
+}
+
+func TestPrettyFileSinkConcurrentWrites(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	dir := t.TempDir()
+	cfg := newSinkTestConfig()
+	cfg.Sinks = []config.SinkConfig{{
+		Name:     "pretty",
+		Output:   filepath.Join(dir, "app.log"),
+		Format:   config.FormatPrettyText,
+		Rotation: config.RotationConfig{MaxSize: 2000},
+	}}
+
+	closer, err := rootlogger.SetupMultiLogger(cfg, nil)
+	if err != nil {
+		t.Fatalf("Failed to set up sinks: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 50 {
+				slog.Info("Concurrent message", "goroutine", g, "i", i)
+			}
+		})
+	}
+	wg.Wait()
+
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "app*.log"))
+	if len(files) < 2 {
+		t.Fatalf("Expected the pretty sink to rotate, got %v", files)
+	}
+	records := 0
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		records += strings.Count(string(data), "Concurrent message")
+		if !strings.HasPrefix(string(data), "[INFO]") {
+			t.Errorf("Expected %s to start with a whole record, got %.40q", name, data)
+		}
+	}
+	if records != 400 {
+		t.Errorf("Expected 400 records across the rotated files, got %d", records)
+	}
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"testing/slogtest"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+)
+
+func TestSlogtestConformance(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := newFormatTestConfig(config.FormatJSON)
+	cfg.Level = "debug"
+	handler := customhandler.NewHandler(cfg, nil, &buf)
+
+	results := func() []map[string]any {
+		var records []map[string]any
+		for _, record := range decodeJSONLines(t, buf.String()) {
+			records = append(records, slogtestRecord(record))
+		}
+		return records
+	}
+
+	if err := slogtest.TestHandler(handler, results); err != nil {
+		t.Error(err)
+	}
+}
+
+// slogtestRecord maps the built-in keys of the JSON backend to the ones
+// slogtest expects
+func slogtestRecord(record map[string]any) map[string]any {
+	for key, name := range map[string]string{"timestamp": slog.TimeKey, "message": slog.MessageKey} {
+		if value, ok := record[key]; ok {
+			record[name] = value
+			delete(record, key)
+		}
+	}
+	return record
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aaffriya/logger/config"
+	customhandler "github.com/aaffriya/logger/internal/handler"
+)
+
+func logThemeTest(theme config.ThemeConfig) string {
+	var buf bytes.Buffer
+	logger := newPrettyTestLogger(&buf, config.FormatPrettyText, config.PrettyConfig{Color: config.ColorAlways, Theme: theme})
+
+	logger.Error("Themed", "status", 503)
+	return buf.String()
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	outputs := map[string]string{}
+	for _, name := range []string{config.ThemeDark, config.ThemeLight, config.ThemeHighContrast} {
+		output := logThemeTest(config.ThemeConfig{Name: name})
+		for other, previous := range outputs {
+			if output == previous {
+				t.Errorf("Expected the %s and %s themes to differ, got: %q", name, other, output)
+			}
+		}
+		outputs[name] = output
+
+		if stripANSI(output) != stripANSI(outputs[config.ThemeDark]) {
+			t.Errorf("Expected the %s theme to keep the layout, got: %q", name, output)
+		}
+	}
+
+	if logThemeTest(config.ThemeConfig{}) != outputs[config.ThemeDark] {
+		t.Error("Expected dark to be the default theme")
+	}
+}
+
+func TestThemeColorDefinitions(t *testing.T) {
+	output := logThemeTest(config.ThemeConfig{
+		Message: "bold #ff8700",
+		Levels:  config.LevelColors{Error: "italic 196"},
+		Fields:  map[string]string{"status": "bright-blue"},
+	})
+
+	for _, want := range []string{"\x1b[1;38;2;255;135;0mThemed", "\x1b[3;38;5;196mERROR", "\x1b[94m503"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got: %q", want, output)
+		}
+	}
+}
+
+func TestThemesPerHandler(t *testing.T) {
+	var dark, light bytes.Buffer
+	cfg := newFormatTestConfig(config.FormatPrettyText)
+	cfg.Pretty.Color = config.ColorAlways
+	handler := customhandler.NewSinkHandler(cfg, nil,
+		customhandler.Sink{Writer: &dark},
+		customhandler.Sink{Config: config.SinkConfig{Pretty: &config.PrettyConfig{
+			Color: config.ColorAlways,
+			Theme: config.ThemeConfig{Name: config.ThemeLight},
+		}}, Writer: &light},
+	)
+
+	slog.New(handler).Error("Two themes")
+
+	if dark.String() == light.String() || stripANSI(dark.String()) != stripANSI(light.String()) {
+		t.Errorf("Expected the same record in two themes, got: %q and %q", dark.String(), light.String())
+	}
+}